@@ -26,27 +26,31 @@ type StandardClaims struct {
 }
 
 // Validates time based claims "exp, iat, nbf".
-// There is no accounting for clock skew.
+// There is no accounting for clock skew.  Use Parser.Leeway to allow for it.
 // As well, if any of the above claims are not in the token, it will still
 // be considered a valid claim.
 func (c StandardClaims) Valid() error {
+	return c.valid(validator{})
+}
+
+func (c StandardClaims) valid(v validator) error {
 	vErr := new(ValidationError)
 	now := TimeFunc().Unix()
 
 	// The claims below are optional, by default, so if they are set to the
 	// default value in Go, let's not fail the verification for them.
-	if c.VerifyExpiresAt(now, false) == false {
+	if c.VerifyExpiresAt(now-leewaySeconds(v.expLeeway), false) == false {
 		delta := time.Unix(now, 0).Sub(time.Unix(c.ExpiresAt, 0))
 		vErr.Inner = fmt.Errorf("token is expired by %v", delta)
 		vErr.Errors |= ValidationErrorExpired
 	}
 
-	if c.VerifyIssuedAt(now, false) == false {
+	if c.VerifyIssuedAt(now+leewaySeconds(v.iatLeeway), false) == false {
 		vErr.Inner = fmt.Errorf("Token used before issued")
 		vErr.Errors |= ValidationErrorIssuedAt
 	}
 
-	if c.VerifyNotBefore(now, false) == false {
+	if c.VerifyNotBefore(now+leewaySeconds(v.nbfLeeway), false) == false {
 		vErr.Inner = fmt.Errorf("token is not valid yet")
 		vErr.Errors |= ValidationErrorNotValidYet
 	}
@@ -61,7 +65,7 @@ func (c StandardClaims) Valid() error {
 // Compares the aud claim against cmp.
 // If required is false, this method will return true if the value matches or is unset
 func (c *StandardClaims) VerifyAudience(cmp string, req bool) bool {
-	return verifyAud(c.Audience, cmp, req)
+	return verifyAud([]string{c.Audience}, cmp, req)
 }
 
 // Compares the exp claim against cmp.
@@ -88,17 +92,99 @@ func (c *StandardClaims) VerifyNotBefore(cmp int64, req bool) bool {
 	return verifyNbf(c.NotBefore, cmp, req)
 }
 
+func (c StandardClaims) expiresAt() int64 {
+	return c.ExpiresAt
+}
+
+func (c *StandardClaims) setTimeClaim(name string, unix int64) {
+	switch name {
+	case "exp":
+		c.ExpiresAt = unix
+	case "iat":
+		c.IssuedAt = unix
+	case "nbf":
+		c.NotBefore = unix
+	}
+}
+
 // ----- helpers
 
-func verifyAud(aud string, cmp string, required bool) bool {
-	if aud == "" {
-		return !required
+// Settings from the Parser that affect validation of the standard claims.
+// The zero value gives the strict behavior of Valid.
+type validator struct {
+	expLeeway, nbfLeeway, iatLeeway time.Duration
+}
+
+func leewaySeconds(leeway time.Duration) int64 {
+	return int64(leeway / time.Second)
+}
+
+// Implemented by the built in claims types so the Parser can apply its
+// settings.  See Parser.Leeway.
+type validatorClaims interface {
+	valid(v validator) error
+}
+
+// Returns claims as a validatorClaims if its concrete type is one of the built
+// in claims types.  A type embedding one also has valid, through promotion,
+// but may override Valid with checks of its own that must not be skipped.
+func builtinValidatorClaims(claims Claims) (validatorClaims, bool) {
+	switch c := claims.(type) {
+	case MapClaims, StandardClaims, *StandardClaims, RegisteredClaims, *RegisteredClaims, ExtendedClaims, *ExtendedClaims:
+		return c.(validatorClaims), true
 	}
-	if subtle.ConstantTimeCompare([]byte(aud), []byte(cmp)) != 0 {
-		return true
-	} else {
-		return false
+	return nil, false
+}
+
+// Implemented by claims types of your own, such as one embedding
+// RegisteredClaims, to have the Parser's leeway applied.  When any leeway is
+// set, the Parser calls ValidWithLeeway instead of Valid, with the leeway for
+// exp, nbf and iat.  Parsing any other type of your own then fails with
+// ErrLeewayUnsupported, rather than ignoring the leeway.  ValidateWithLeeway
+// does the checks for the embedded claims:
+//
+//	func (c MyClaims) ValidWithLeeway(exp, nbf, iat time.Duration) error {
+//		if c.Role != "admin" {
+//			return errors.New("not admin")
+//		}
+//		return jwt.ValidateWithLeeway(c.RegisteredClaims, exp, nbf, iat)
+//	}
+type LeewayClaims interface {
+	Claims
+	ValidWithLeeway(exp, nbf, iat time.Duration) error
+}
+
+// Validates claims, which must be MapClaims, StandardClaims, RegisteredClaims
+// or ExtendedClaims, or a pointer to one, as Valid does but allowing the given
+// leeway for the exp, nbf and iat claims.  For other types it returns
+// ErrLeewayUnsupported.
+func ValidateWithLeeway(claims Claims, exp, nbf, iat time.Duration) error {
+	c, ok := builtinValidatorClaims(claims)
+	if !ok {
+		return ErrLeewayUnsupported
+	}
+	return c.valid(validator{expLeeway: exp, nbfLeeway: nbf, iatLeeway: iat})
+}
+
+func verifyAud(aud []string, cmp string, required bool) bool {
+	// Compare against every entry, rather than returning on the first match,
+	// to keep the comparison constant time
+	result := false
+	empty := true
+	for _, a := range aud {
+		if subtle.ConstantTimeCompare([]byte(a), []byte(cmp)) != 0 {
+			result = true
+		}
+		if a != "" {
+			empty = false
+		}
+	}
+
+	// No audience, or only empty values, counts as unset
+	if empty {
+		return !required
 	}
+	return result
 }
 
 func verifyExp(exp int64, now int64, required bool) bool {
@@ -0,0 +1,80 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var standardClaimsMarshalTestData = []struct {
+	name     string
+	claims   jwt.StandardClaims
+	expected string
+}{
+	{
+		"empty",
+		jwt.StandardClaims{},
+		`{}`,
+	},
+	{
+		"all fields",
+		jwt.StandardClaims{
+			Audience:  "api",
+			ExpiresAt: 1500,
+			Id:        "abc",
+			IssuedAt:  1000,
+			Issuer:    "test",
+			NotBefore: 1100,
+			Subject:   "user",
+		},
+		`{"aud":"api","exp":1500,"jti":"abc","iat":1000,"iss":"test","nbf":1100,"sub":"user"}`,
+	},
+}
+
+func TestStandardClaims_Marshal(t *testing.T) {
+	for _, data := range standardClaimsMarshalTestData {
+		b, err := json.Marshal(data.claims)
+		if err != nil {
+			t.Errorf("[%v] Error while marshaling: %v", data.name, err)
+			continue
+		}
+		if string(b) != data.expected {
+			t.Errorf("[%v] Incorrect JSON.\nwas:\n%s\nexpecting:\n%v", data.name, b, data.expected)
+		}
+	}
+}
+
+func TestStandardClaims_Valid(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	var validTestData = []struct {
+		name   string
+		claims jwt.StandardClaims
+		errors uint32
+	}{
+		{"no claims", jwt.StandardClaims{}, 0},
+		{"current", jwt.StandardClaims{ExpiresAt: 2000, IssuedAt: 900, NotBefore: 900}, 0},
+		{"expired", jwt.StandardClaims{ExpiresAt: 999}, jwt.ValidationErrorExpired},
+		{"not valid yet", jwt.StandardClaims{NotBefore: 1001}, jwt.ValidationErrorNotValidYet},
+		{"issued in the future", jwt.StandardClaims{IssuedAt: 1001}, jwt.ValidationErrorIssuedAt},
+	}
+
+	for _, data := range validTestData {
+		var err error
+		at(now, func() {
+			err = data.claims.Valid()
+		})
+
+		if data.errors == 0 {
+			if err != nil {
+				t.Errorf("[%v] Error while validating claims: %v", data.name, err)
+			}
+			continue
+		}
+		if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != data.errors {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, data.errors)
+		}
+	}
+}
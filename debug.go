@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Returns a human readable description of a token for support and debugging:
+// its algorithm, header, and claims, with exp, iat and nbf shown as RFC 3339
+// times and the time left until expiry.  The signature is NOT checked, and
+// the output says so; never base a decision on it.  The error is the one
+// from ParseUnverified, for tokens too malformed to describe.
+func DumpUnverified(tokenString string) (string, error) {
+	parser := &Parser{UseJSONNumber: true}
+	token, _, err := parser.ParseUnverified(tokenString, MapClaims{})
+	if err != nil {
+		// An unknown or missing alg doesn't stop the token being described
+		if ve, ok := err.(*ValidationError); !ok || !isAlgError(ve) {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("UNVERIFIED TOKEN: the signature has not been checked\n")
+	fmt.Fprintf(&buf, "Algorithm: %v\n", dumpValue(token.Header["alg"]))
+
+	buf.WriteString("Header:\n")
+	dumpMap(&buf, token.Header, nil)
+
+	claims := token.Claims.(MapClaims)
+	buf.WriteString("Claims:\n")
+	dumpMap(&buf, claims, dumpTime)
+
+	exp, present, err := numericDate(claims["exp"])
+	switch left := time.Unix(exp, 0).Sub(TimeFunc()); {
+	case !present:
+		buf.WriteString("Expires: never (no exp claim)\n")
+	case err != nil:
+		buf.WriteString("Expires: unknown (exp is not a valid numeric date)\n")
+	case left > 0:
+		fmt.Fprintf(&buf, "Expires in: %v\n", left.Round(time.Second))
+	default:
+		fmt.Fprintf(&buf, "Expired: %v ago\n", (-left).Round(time.Second))
+	}
+	return buf.String(), nil
+}
+
+// Reports whether err is from looking up the alg, the last step before the
+// token is fully decoded
+func isAlgError(err *ValidationError) bool {
+	switch err.Inner {
+	case ErrSigningMethodUnavailable, ErrAlgMissing, ErrAlgNotString, ErrAlgEmpty:
+		return true
+	}
+	return false
+}
+
+// Writes m, sorted by key.  describe may add to a value's description.
+func dumpMap(buf *bytes.Buffer, m map[string]interface{}, describe func(name string, value interface{}) string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(buf, "  %v: %v", k, dumpValue(m[k]))
+		if describe != nil {
+			if s := describe(k, m[k]); s != "" {
+				fmt.Fprintf(buf, " (%v)", s)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+func dumpValue(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+	return string(b)
+}
+
+// Renders the time based claims as RFC 3339
+func dumpTime(name string, value interface{}) string {
+	if name != "exp" && name != "iat" && name != "nbf" {
+		return ""
+	}
+	v, present, err := numericDate(value)
+	if !present {
+		return ""
+	}
+	if err != nil {
+		return "not a valid numeric date"
+	}
+	return time.Unix(v, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,88 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestDumpUnverified(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user",
+		"exp":   1500,
+		"iat":   1000,
+		"nbf":   "garbage",
+		"roles": []string{"admin"},
+		"big":   json.Number("12345678901234567890"),
+	})
+	token.Header["kid"] = "key-1"
+	tokenString, err := token.SignedString(hmacTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dump string
+	at(time.Unix(1200, 0), func() {
+		dump, err = jwt.DumpUnverified(tokenString)
+	})
+	if err != nil {
+		t.Fatalf("Error dumping token: %v", err)
+	}
+
+	for _, expected := range []string{
+		"UNVERIFIED",
+		"Algorithm: \"HS256\"",
+		"  kid: \"key-1\"",
+		"  typ: \"JWT\"",
+		"  sub: \"user\"",
+		"  roles: [\"admin\"]",
+		"  big: 12345678901234567890",
+		"  exp: 1500 (1970-01-01T00:25:00Z)",
+		"  iat: 1000 (1970-01-01T00:16:40Z)",
+		"  nbf: \"garbage\" (not a valid numeric date)",
+		"Expires in: 5m0s",
+	} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("Expected %q in dump:\n%v", expected, dump)
+		}
+	}
+}
+
+func TestDumpUnverified_Odd(t *testing.T) {
+	var dumpTestData = []struct {
+		name     string
+		header   string
+		claims   string
+		expected string
+	}{
+		{"expired", `{"alg":"HS256"}`, `{"exp":1000}`, "Expired: 3m20s ago"},
+		{"no expiry", `{"alg":"HS256"}`, `{"sub":"user"}`, "Expires: never"},
+		{"unknown alg", `{"alg":"XX999"}`, `{"sub":"user"}`, "Algorithm: \"XX999\""},
+		{"missing alg", `{}`, `{"sub":"user"}`, "Algorithm: null"},
+		{"nested claims", `{"alg":"HS256"}`, `{"exp":{"a":[1,null]}}`, "  exp: {\"a\":[1,null]} (not a valid numeric date)"},
+		{"invalid expiry", `{"alg":"HS256"}`, `{"exp":"soon"}`, "Expires: unknown"},
+	}
+
+	for _, data := range dumpTestData {
+		tokenString := jwt.EncodeSegment([]byte(data.header)) + "." + jwt.EncodeSegment([]byte(data.claims)) + ".c2ln"
+		var dump string
+		var err error
+		at(time.Unix(1200, 0), func() {
+			dump, err = jwt.DumpUnverified(tokenString)
+		})
+		if err != nil {
+			t.Errorf("[%v] Error dumping token: %v", data.name, err)
+			continue
+		}
+		if !strings.Contains(dump, data.expected) {
+			t.Errorf("[%v] Expected %q in dump:\n%v", data.name, data.expected, dump)
+		}
+	}
+
+	if _, err := jwt.DumpUnverified("not a token"); err == nil {
+		t.Errorf("Expected an error for a malformed token")
+	}
+}
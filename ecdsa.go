@@ -4,7 +4,10 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/rand"
+	"encoding/asn1"
 	"errors"
+	"hash"
+	"io"
 	"math/big"
 )
 
@@ -14,7 +17,8 @@ var (
 )
 
 // Implements the ECDSA family of signing methods signing methods
-// Expects *ecdsa.PrivateKey for signing and *ecdsa.PublicKey for verification
+// Expects *ecdsa.PrivateKey, or a crypto.Signer with an ECDSA key, for signing
+// and *ecdsa.PublicKey for verification
 type SigningMethodECDSA struct {
 	Name      string
 	Hash      crypto.Hash
@@ -53,9 +57,23 @@ func (m *SigningMethodECDSA) Alg() string {
 	return m.Name
 }
 
+// Implements HashFuncer
+func (m *SigningMethodECDSA) HashFunc() crypto.Hash {
+	return m.Hash
+}
+
+func (m *SigningMethodECDSA) checkVerificationKey(key interface{}) (string, bool) {
+	_, ok := key.(*ecdsa.PublicKey)
+	return "*ecdsa.PublicKey", ok
+}
+
 // Implements the Verify method from SigningMethod
 // For this verify method, key must be an ecdsa.PublicKey struct
 func (m *SigningMethodECDSA) Verify(signingString, signature string, key interface{}) error {
+	if isNilKey(key) {
+		return ErrNilKey
+	}
+
 	var err error
 
 	// Decode the signature
@@ -74,7 +92,7 @@ func (m *SigningMethodECDSA) Verify(signingString, signature string, key interfa
 	}
 
 	if len(sig) != 2*m.KeySize {
-		return ErrECDSAVerification
+		return &SignatureLengthError{Alg: m.Alg(), Length: len(sig), Expected: 2 * m.KeySize}
 	}
 
 	r := big.NewInt(0).SetBytes(sig[:m.KeySize])
@@ -96,53 +114,112 @@ func (m *SigningMethodECDSA) Verify(signingString, signature string, key interfa
 }
 
 // Implements the Sign method from SigningMethod
-// For this signing method, key must be an ecdsa.PrivateKey struct
+// For this signing method, key must be an ecdsa.PrivateKey struct, or a
+// crypto.Signer whose public key is an *ecdsa.PublicKey, such as an HSM key.
 func (m *SigningMethodECDSA) Sign(signingString string, key interface{}) (string, error) {
+	return m.SignWithRand(signingString, key, rand.Reader)
+}
+
+// Like Sign, but draws the nonce from random instead of crypto/rand.  Never
+// use anything but a cryptographically secure reader outside of tests.
+//
+// Since Go 1.26, crypto/ecdsa ignores random and uses crypto/rand unless the
+// program runs with GODEBUG=cryptocustomrand=1.  This package only sets that
+// for its own tests.  To make signatures reproducible in your tests, use
+// testing/cryptotest.SetGlobalRandom instead.
+func (m *SigningMethodECDSA) SignWithRand(signingString string, key interface{}, random io.Reader) (string, error) {
+	hasher, err := m.signingHash(key)
+	if err != nil {
+		return "", err
+	}
+	hasher.Write([]byte(signingString))
+	return m.signDigest(random, hasher.Sum(nil), key)
+}
+
+func (m *SigningMethodECDSA) signingHash(key interface{}) (hash.Hash, error) {
+	if isNilKey(key) {
+		return nil, ErrNilKey
+	}
+
 	// Get the key
-	var ecdsaKey *ecdsa.PrivateKey
+	var curveBits int
 	switch k := key.(type) {
 	case *ecdsa.PrivateKey:
-		ecdsaKey = k
+		curveBits = k.Curve.Params().BitSize
+	case crypto.Signer:
+		pub, ok := k.Public().(*ecdsa.PublicKey)
+		if !ok {
+			return nil, ErrInvalidKeyType
+		}
+		curveBits = pub.Curve.Params().BitSize
 	default:
-		return "", ErrInvalidKeyType
+		return nil, ErrInvalidKeyType
+	}
+
+	if m.CurveBits != curveBits {
+		return nil, ErrInvalidKey
 	}
 
 	// Create the hasher
 	if !m.Hash.Available() {
-		return "", ErrHashUnavailable
+		return nil, ErrHashUnavailable
 	}
+	return m.Hash.New(), nil
+}
 
-	hasher := m.Hash.New()
-	hasher.Write([]byte(signingString))
-
-	// Sign the string and return r, s
-	if r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, hasher.Sum(nil)); err == nil {
-		curveBits := ecdsaKey.Curve.Params().BitSize
+// Sign the digest and return r, s
+func (m *SigningMethodECDSA) signDigest(random io.Reader, digest []byte, key interface{}) (string, error) {
+	var r, s *big.Int
+	var err error
+	if ecdsaKey, ok := key.(*ecdsa.PrivateKey); ok {
+		r, s, err = ecdsa.Sign(random, ecdsaKey, digest)
+	} else {
+		r, s, err = signWithSigner(key.(crypto.Signer), random, digest, m.Hash)
+	}
+	if err != nil {
+		return "", err
+	}
 
-		if m.CurveBits != curveBits {
-			return "", ErrInvalidKey
-		}
+	keyBytes := m.CurveBits / 8
+	if m.CurveBits%8 > 0 {
+		keyBytes += 1
+	}
 
-		keyBytes := curveBits / 8
-		if curveBits%8 > 0 {
-			keyBytes += 1
-		}
+	// We serialize the outpus (r and s) into big-endian byte arrays and pad
+	// them with zeros on the left to make sure the sizes work out. Both arrays
+	// must be keyBytes long, and the output must be 2*keyBytes long.
+	rBytes := r.Bytes()
+	rBytesPadded := make([]byte, keyBytes)
+	copy(rBytesPadded[keyBytes-len(rBytes):], rBytes)
 
-		// We serialize the outpus (r and s) into big-endian byte arrays and pad
-		// them with zeros on the left to make sure the sizes work out. Both arrays
-		// must be keyBytes long, and the output must be 2*keyBytes long.
-		rBytes := r.Bytes()
-		rBytesPadded := make([]byte, keyBytes)
-		copy(rBytesPadded[keyBytes-len(rBytes):], rBytes)
+	sBytes := s.Bytes()
+	sBytesPadded := make([]byte, keyBytes)
+	copy(sBytesPadded[keyBytes-len(sBytes):], sBytes)
 
-		sBytes := s.Bytes()
-		sBytesPadded := make([]byte, keyBytes)
-		copy(sBytesPadded[keyBytes-len(sBytes):], sBytes)
+	out := append(rBytesPadded, sBytesPadded...)
 
-		out := append(rBytesPadded, sBytesPadded...)
+	return EncodeSegment(out), nil
+}
 
-		return EncodeSegment(out), nil
-	} else {
-		return "", err
+// Signs digest with signer, converting its ASN.1 signature into r and s.
+// These come from outside the package, so they're checked to be in range for
+// the signer's curve before they're serialized.
+func signWithSigner(signer crypto.Signer, random io.Reader, digest []byte, hash crypto.Hash) (*big.Int, *big.Int, error) {
+	der, err := signer.Sign(random, digest, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sig struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, nil, err
+	} else if len(rest) != 0 {
+		return nil, nil, errors.New("crypto/ecdsa: trailing data after signature")
+	}
+	n := signer.Public().(*ecdsa.PublicKey).Curve.Params().N
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Cmp(n) >= 0 {
+		return nil, nil, errors.New("crypto/ecdsa: signer returned r or s out of range")
 	}
+	return sig.R, sig.S, nil
 }
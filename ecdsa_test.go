@@ -95,6 +95,89 @@ func TestECDSASign(t *testing.T) {
 			if sig == parts[2] {
 				t.Errorf("[%v] Identical signatures\nbefore:\n%v\nafter:\n%v", data.name, parts[2], sig)
 			}
+
+			// The fresh signature must round trip against the public half
+			err = method.Verify(strings.Join(parts[0:2], "."), sig, &ecdsaKey.PublicKey)
+			if err != nil {
+				t.Errorf("[%v] Error while verifying signature: %v", data.name, err)
+			}
+		}
+	}
+}
+
+// Deterministic stand in for crypto/rand.  A constant stream keeps the output
+// stable even if the signer reads a varying number of bytes.
+type fixedReader byte
+
+func (r fixedReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestECDSASignWithRand(t *testing.T) {
+	for _, data := range ecdsaTestData {
+		if !data.valid {
+			continue
+		}
+
+		key, _ := ioutil.ReadFile(data.keys["private"])
+		ecdsaKey, err := jwt.ParseECPrivateKeyFromPEM(key)
+		if err != nil {
+			t.Errorf("[%v] Unable to parse ECDSA private key: %v", data.name, err)
+			continue
+		}
+
+		parts := strings.Split(data.tokenString, ".")
+		signingString := strings.Join(parts[0:2], ".")
+		method := jwt.GetSigningMethod(data.alg).(*jwt.SigningMethodECDSA)
+
+		first, err := method.SignWithRand(signingString, ecdsaKey, fixedReader(1))
+		if err != nil {
+			t.Errorf("[%v] Error signing token: %v", data.name, err)
+			continue
+		}
+		second, _ := method.SignWithRand(signingString, ecdsaKey, fixedReader(1))
+		if first != second {
+			t.Errorf("[%v] Signatures from the same reader differ\nfirst:\n%v\nsecond:\n%v", data.name, first, second)
+		}
+		if other, _ := method.SignWithRand(signingString, ecdsaKey, fixedReader(2)); other == first {
+			t.Errorf("[%v] Signatures from different readers match", data.name)
+		}
+		if err := method.Verify(signingString, first, &ecdsaKey.PublicKey); err != nil {
+			t.Errorf("[%v] Error while verifying signature: %v", data.name, err)
+		}
+	}
+}
+
+func TestECDSASignatureLength(t *testing.T) {
+	for _, data := range ecdsaTestData {
+		if !data.valid {
+			continue
+		}
+
+		key, _ := ioutil.ReadFile(data.keys["public"])
+		ecdsaKey, err := jwt.ParseECPublicKeyFromPEM(key)
+		if err != nil {
+			t.Errorf("[%v] Unable to parse ECDSA public key: %v", data.name, err)
+			continue
+		}
+
+		parts := strings.Split(data.tokenString, ".")
+		sig, _ := jwt.DecodeSegment(parts[2])
+		for _, mangled := range [][]byte{sig[:len(sig)-1], append(sig, 0)} {
+			tokenString := strings.Join(parts[0:2], ".") + "." + jwt.EncodeSegment(mangled)
+			_, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return ecdsaKey, nil })
+
+			ve, ok := err.(*jwt.ValidationError)
+			if !ok || ve.Errors != jwt.ValidationErrorSignatureInvalid {
+				t.Errorf("[%v] Expected ValidationErrorSignatureInvalid.  Got %v", data.name, err)
+				continue
+			}
+			if le, ok := ve.Inner.(*jwt.SignatureLengthError); !ok || le.Length != len(mangled) || le.Expected != len(sig) {
+				t.Errorf("[%v] Expected a SignatureLengthError.  Got %v", data.name, ve.Inner)
+			}
 		}
 	}
 }
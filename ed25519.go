@@ -0,0 +1,94 @@
+//go:build go1.13
+// +build go1.13
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+)
+
+var (
+	ErrEd25519Verification = errors.New("ed25519: verification error")
+)
+
+// Implements the EdDSA signing method with Ed25519 keys, as described in
+// https://tools.ietf.org/html/rfc8037
+// Expects ed25519.PrivateKey for signing and ed25519.PublicKey for validation
+type SigningMethodEd25519 struct{}
+
+// Specific instance for EdDSA
+var (
+	SigningMethodEdDSA *SigningMethodEd25519
+)
+
+func init() {
+	SigningMethodEdDSA = &SigningMethodEd25519{}
+	RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *SigningMethodEd25519) Alg() string {
+	return "EdDSA"
+}
+
+// Implements HashFuncer.  Ed25519 hashes internally, so this is crypto.Hash(0).
+func (m *SigningMethodEd25519) HashFunc() crypto.Hash {
+	return 0
+}
+
+func (m *SigningMethodEd25519) checkVerificationKey(key interface{}) (string, bool) {
+	_, ok := key.(ed25519.PublicKey)
+	return "ed25519.PublicKey", ok
+}
+
+// Implements the Verify method from SigningMethod
+// For this verify method, key must be an ed25519.PublicKey
+func (m *SigningMethodEd25519) Verify(signingString, signature string, key interface{}) error {
+	var err error
+
+	var ed25519Key ed25519.PublicKey
+	var ok bool
+	if ed25519Key, ok = key.(ed25519.PublicKey); key == nil || ok && ed25519Key == nil {
+		return ErrNilKey
+	} else if !ok {
+		return ErrInvalidKeyType
+	}
+	if len(ed25519Key) != ed25519.PublicKeySize {
+		return ErrInvalidKey
+	}
+
+	// Decode the signature
+	var sig []byte
+	if sig, err = DecodeSegment(signature); err != nil {
+		return err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return &SignatureLengthError{Alg: m.Alg(), Length: len(sig), Expected: ed25519.SignatureSize}
+	}
+
+	// Ed25519 hashes the message itself, so verify the raw signing string
+	if !ed25519.Verify(ed25519Key, []byte(signingString), sig) {
+		return ErrEd25519Verification
+	}
+	return nil
+}
+
+// Implements the Sign method from SigningMethod
+// For this signing method, key must be an ed25519.PrivateKey
+func (m *SigningMethodEd25519) Sign(signingString string, key interface{}) (string, error) {
+	var ed25519Key ed25519.PrivateKey
+	var ok bool
+	if ed25519Key, ok = key.(ed25519.PrivateKey); key == nil || ok && ed25519Key == nil {
+		return "", ErrNilKey
+	} else if !ok {
+		return "", ErrInvalidKeyType
+	}
+	if len(ed25519Key) != ed25519.PrivateKeySize {
+		return "", ErrInvalidKey
+	}
+
+	return EncodeSegment(ed25519.Sign(ed25519Key, []byte(signingString))), nil
+}
@@ -0,0 +1,148 @@
+//go:build go1.13
+// +build go1.13
+
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Key and token from https://tools.ietf.org/html/rfc8037#appendix-A.4
+var (
+	ed25519TestSeed      = "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"
+	ed25519TestPublicKey = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+)
+
+var ed25519TestData = []struct {
+	name        string
+	tokenString string
+	valid       bool
+}{
+	{
+		"RFC 8037 example",
+		"eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg",
+		true,
+	},
+	{
+		"RFC 8037 example: invalid",
+		"eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.igyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg",
+		false,
+	},
+	{
+		"short signature",
+		"eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0K",
+		false,
+	},
+}
+
+func loadEd25519TestKeys(t *testing.T) (ed25519.PrivateKey, ed25519.PublicKey) {
+	seed, err := jwt.DecodeSegment(ed25519TestSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := jwt.DecodeSegment(ed25519TestPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ed25519.NewKeyFromSeed(seed), ed25519.PublicKey(pub)
+}
+
+func TestEd25519Verify(t *testing.T) {
+	_, publicKey := loadEd25519TestKeys(t)
+
+	for _, data := range ed25519TestData {
+		parts := strings.Split(data.tokenString, ".")
+
+		method := jwt.GetSigningMethod("EdDSA")
+		err := method.Verify(strings.Join(parts[0:2], "."), parts[2], publicKey)
+		if data.valid && err != nil {
+			t.Errorf("[%v] Error while verifying key: %v", data.name, err)
+		}
+		if !data.valid && err == nil {
+			t.Errorf("[%v] Invalid key passed validation", data.name)
+		}
+	}
+}
+
+func TestEd25519Sign(t *testing.T) {
+	privateKey, _ := loadEd25519TestKeys(t)
+
+	for _, data := range ed25519TestData {
+		if data.valid {
+			// Ed25519 signatures are deterministic, so they must match exactly
+			parts := strings.Split(data.tokenString, ".")
+			sig, err := jwt.SigningMethodEdDSA.Sign(strings.Join(parts[0:2], "."), privateKey)
+			if err != nil {
+				t.Errorf("[%v] Error signing token: %v", data.name, err)
+			}
+			if sig != parts[2] {
+				t.Errorf("[%v] Incorrect signature.\nwas:\n%v\nexpecting:\n%v", data.name, sig, parts[2])
+			}
+		}
+	}
+}
+
+func TestEd25519SignatureLength(t *testing.T) {
+	_, publicKey := loadEd25519TestKeys(t)
+	parts := strings.Split(ed25519TestData[0].tokenString, ".")
+	sig, _ := jwt.DecodeSegment(parts[2])
+
+	for _, mangled := range [][]byte{sig[:63], append(sig, 0)} {
+		err := jwt.SigningMethodEdDSA.Verify(strings.Join(parts[0:2], "."), jwt.EncodeSegment(mangled), publicKey)
+		if le, ok := err.(*jwt.SignatureLengthError); !ok || le.Length != len(mangled) || le.Expected != 64 {
+			t.Errorf("Expected a SignatureLengthError for %v bytes.  Got %v", len(mangled), err)
+		}
+	}
+}
+
+func TestEd25519InvalidKeyType(t *testing.T) {
+	privateKey, publicKey := loadEd25519TestKeys(t)
+	parts := strings.Split(ed25519TestData[0].tokenString, ".")
+	signingString := strings.Join(parts[0:2], ".")
+
+	if _, err := jwt.SigningMethodEdDSA.Sign(signingString, publicKey); err != jwt.ErrInvalidKeyType {
+		t.Errorf("Expected ErrInvalidKeyType while signing with a public key.  Got %v", err)
+	}
+	if err := jwt.SigningMethodEdDSA.Verify(signingString, parts[2], privateKey); err != jwt.ErrInvalidKeyType {
+		t.Errorf("Expected ErrInvalidKeyType while verifying with a private key.  Got %v", err)
+	}
+	if err := jwt.SigningMethodEdDSA.Verify(signingString, parts[2], publicKey[:16]); err != jwt.ErrInvalidKey {
+		t.Errorf("Expected ErrInvalidKey for a truncated public key.  Got %v", err)
+	}
+}
+
+func TestEd25519ParseKeyTypeMismatch(t *testing.T) {
+	privateKey, _ := loadEd25519TestKeys(t)
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"foo": "bar"}).SignedString(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []interface{}{privateKey, []byte("secret")} {
+		_, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return key, nil })
+		if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorUnverifiable || ve.Inner != jwt.ErrInvalidKeyType {
+			t.Errorf("[%T] Expected ValidationErrorUnverifiable.  Got %v", key, err)
+		}
+	}
+}
+
+func TestEd25519RoundTrip(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"foo": "bar"}).SignedString(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return publicKey, nil })
+	if err != nil || !token.Valid {
+		t.Errorf("Error while verifying token: %v", err)
+	}
+}
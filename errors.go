@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"errors"
+	"fmt"
 )
 
 // Error constants
@@ -9,6 +10,29 @@ var (
 	ErrInvalidKey      = errors.New("key is invalid")
 	ErrInvalidKeyType  = errors.New("key is of invalid type")
 	ErrHashUnavailable = errors.New("the requested hash function is unavailable")
+	ErrNilKey          = errors.New("nil key provided")
+
+	// Sentinels matching ValidationError flags with errors.Is.
+	// ErrSignatureInvalid, defined with the HMAC methods, is matched too.
+	ErrTokenMalformed           = errors.New("token is malformed")
+	ErrTokenExpired             = errors.New("token is expired")
+	ErrTokenNotValidYet         = errors.New("token is not valid yet")
+	ErrSigningMethodUnavailable = errors.New("signing method (alg) is unavailable")
+
+	// RFC 7797 unencoded payload errors
+	ErrB64NotCritical         = errors.New("b64 header must be listed in crit")
+	ErrUnencodedPayloadPeriod = errors.New("unencoded payload must not contain '.'")
+
+	// Errors for a malformed alg header
+	ErrAlgMissing   = errors.New("no alg header")
+	ErrAlgNotString = errors.New("alg header is not a string")
+	ErrAlgEmpty     = errors.New("empty alg")
+
+	// The zip header belongs to JWE; compressed JWS payloads aren't supported
+	ErrZipNotSupported = errors.New("zip header is not supported in a JWS")
+
+	// A Parser leeway is set, but the claims type can't apply it; see LeewayClaims
+	ErrLeewayUnsupported = errors.New("claims type does not implement LeewayClaims, so the leeway can't be applied")
 )
 
 // The errors that might occur when parsing and validating a token
@@ -27,6 +51,18 @@ const (
 	ValidationErrorClaimsInvalid // Generic claims validation error
 )
 
+// Returned by Verify when the decoded signature is the wrong size for the
+// method, which usually means the token was truncated or mangled
+type SignatureLengthError struct {
+	Alg      string
+	Length   int
+	Expected int
+}
+
+func (e *SignatureLengthError) Error() string {
+	return fmt.Sprintf("%v signature is %v bytes long, expected %v", e.Alg, e.Length, e.Expected)
+}
+
 // Helper for constructing a ValidationError with a string error message
 func NewValidationError(errorText string, errorFlags uint32) *ValidationError {
 	return &ValidationError{
@@ -44,7 +80,9 @@ type ValidationError struct {
 
 // Validation error is an error type
 func (e ValidationError) Error() string {
-	if e.Inner != nil {
+	if e.Inner != nil && e.text != "" {
+		return e.text + ": " + e.Inner.Error()
+	} else if e.Inner != nil {
 		return e.Inner.Error()
 	} else if e.text != "" {
 		return e.text
@@ -53,6 +91,28 @@ func (e ValidationError) Error() string {
 	}
 }
 
+// Reports whether target is the sentinel error for one of the flags set in
+// Errors, so errors.Is(err, ErrTokenExpired) works along with checking
+// Errors&ValidationErrorExpired.
+func (e ValidationError) Is(target error) bool {
+	switch target {
+	case ErrTokenMalformed:
+		return e.Errors&ValidationErrorMalformed != 0
+	case ErrSignatureInvalid:
+		return e.Errors&ValidationErrorSignatureInvalid != 0
+	case ErrTokenExpired:
+		return e.Errors&ValidationErrorExpired != 0
+	case ErrTokenNotValidYet:
+		return e.Errors&ValidationErrorNotValidYet != 0
+	}
+	return false
+}
+
+// Returns the inner error, such as the one from a Keyfunc
+func (e ValidationError) Unwrap() error {
+	return e.Inner
+}
+
 // No errors
 func (e *ValidationError) valid() bool {
 	return e.Errors == 0
@@ -0,0 +1,60 @@
+//go:build go1.13
+// +build go1.13
+
+package jwt_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestValidationError_Is(t *testing.T) {
+	keyFunc := func(*jwt.Token) (interface{}, error) { return hmacTestKey, nil }
+	sign := func(claims jwt.Claims) string {
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(hmacTestKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokenString
+	}
+	valid := sign(jwt.MapClaims{"foo": "bar"})
+
+	var isTestData = []struct {
+		name        string
+		tokenString string
+		keyFunc     jwt.Keyfunc
+		sentinel    error
+	}{
+		{"expired", sign(jwt.MapClaims{"exp": float64(500)}), keyFunc, jwt.ErrTokenExpired},
+		{"not valid yet", sign(jwt.MapClaims{"nbf": float64(2000)}), keyFunc, jwt.ErrTokenNotValidYet},
+		{"signature invalid", valid, func(*jwt.Token) (interface{}, error) { return []byte("wrong"), nil }, jwt.ErrSignatureInvalid},
+		{"malformed", "not.a.token", keyFunc, jwt.ErrTokenMalformed},
+		{"signing method unavailable", jwt.EncodeSegment([]byte(`{"alg":"XX999"}`)) + ".e30.sig", keyFunc, jwt.ErrSigningMethodUnavailable},
+	}
+
+	sentinels := []error{jwt.ErrTokenExpired, jwt.ErrTokenNotValidYet, jwt.ErrSignatureInvalid, jwt.ErrTokenMalformed, jwt.ErrSigningMethodUnavailable}
+	for _, data := range isTestData {
+		var err error
+		at(time.Unix(1000, 0), func() {
+			_, err = jwt.Parse(data.tokenString, data.keyFunc)
+		})
+		if _, ok := err.(*jwt.ValidationError); !ok {
+			t.Errorf("[%v] Expected a *ValidationError.  Got %T", data.name, err)
+		}
+		for _, sentinel := range sentinels {
+			if is := errors.Is(err, sentinel); is != (sentinel == data.sentinel) {
+				t.Errorf("[%v] errors.Is(err, %q) = %v", data.name, sentinel, is)
+			}
+		}
+	}
+
+	// A Keyfunc's error stays reachable
+	keyFuncError := errors.New("no such key")
+	_, err := jwt.Parse(valid, func(*jwt.Token) (interface{}, error) { return nil, keyFuncError })
+	if !errors.Is(err, keyFuncError) {
+		t.Errorf("Expected the Keyfunc error to be unwrapped.  Got %v", err)
+	}
+}
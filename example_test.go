@@ -77,6 +77,54 @@ func ExampleParseWithClaims_customClaimsType() {
 	// Output: bar 15000
 }
 
+// Example embedding RegisteredClaims in a custom claims type.  Only the
+// embedded struct is needed; its fields are encoded at the top level and its
+// Valid method checks the time based claims.
+func ExampleParseWithClaims_registeredClaims() {
+	type MyCustomClaims struct {
+		Role string `json:"role"`
+		jwt.RegisteredClaims
+	}
+
+	claims := MyCustomClaims{
+		"admin",
+		jwt.RegisteredClaims{Subject: "user", ExpiresAt: jwt.NewNumericDate(time.Unix(15000, 0))},
+	}
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("AllYourBase"))
+
+	at(time.Unix(0, 0), func() {
+		token, err := jwt.ParseWithClaims(tokenString, &MyCustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+			return []byte("AllYourBase"), nil
+		})
+
+		if claims, ok := token.Claims.(*MyCustomClaims); ok && token.Valid {
+			fmt.Println(claims.Role, claims.Subject, claims.ExpiresAt.Time().Unix())
+		} else {
+			fmt.Println(err)
+		}
+	})
+
+	// Output: admin user 15000
+}
+
+// Example using ExtendedClaims for private claims that aren't known ahead of
+// time.  They're encoded alongside the registered claims in one flat object.
+func ExampleExtendedClaims() {
+	claims := jwt.ExtendedClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "test"},
+		Extra:            map[string]interface{}{"tenant": "acme"},
+	}
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("AllYourBase"))
+
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.ExtendedClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte("AllYourBase"), nil
+	})
+
+	parsed := token.Claims.(*jwt.ExtendedClaims)
+	fmt.Println(parsed.Issuer, parsed.Extra["tenant"], err)
+	// Output: test acme <nil>
+}
+
 // Override time value for tests.  Restore default value after.
 func at(t time.Time, f func()) {
 	jwt.TimeFunc = func() time.Time {
@@ -86,6 +134,29 @@ func at(t time.Time, f func()) {
 	jwt.TimeFunc = time.Now
 }
 
+// Example using the "kid" header to select the verification key.  Issuers that
+// rotate keys publish a kid so consumers know which key signed the token.
+func ExampleToken_KeyID() {
+	keys := map[string][]byte{
+		"2019": []byte("OldSecret"),
+		"2020": []byte("NewSecret"),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"})
+	token.Header["kid"] = "2020"
+	tokenString, _ := token.SignedString(keys["2020"])
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if key, ok := keys[token.KeyID()]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown kid %q", token.KeyID())
+	})
+
+	fmt.Println(token.KeyID(), token.Valid, err)
+	// Output: 2020 true <nil>
+}
+
 // An example of parsing the error types using bitfield checks
 func ExampleParse_errorChecking() {
 	// Token from another example.  This token is expired
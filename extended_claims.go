@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The claim names RegisteredClaims encodes
+var registeredClaimNames = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// RegisteredClaims plus any number of extra claims, encoded together as a
+// single flat JSON object with its keys sorted.  The registered claims keep
+// their types and validation, while Extra holds everything else.
+type ExtendedClaims struct {
+	RegisteredClaims
+	Extra map[string]interface{}
+}
+
+func (c ExtendedClaims) MarshalJSON() ([]byte, error) {
+	b, err := Marshal(c.RegisteredClaims)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the registered claims exactly as encoded
+	var registered map[string]json.RawMessage
+	if err = Unmarshal(b, &registered); err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{}, len(registered)+len(c.Extra))
+	for k, v := range registered {
+		merged[k] = v
+	}
+
+	for _, name := range registeredClaimNames {
+		if _, ok := c.Extra[name]; ok {
+			return nil, fmt.Errorf("extra claim %q collides with a registered claim", name)
+		}
+	}
+	for k, v := range c.Extra {
+		merged[k] = v
+	}
+
+	// encoding/json, the default Marshal, sorts map keys, which keeps the
+	// output stable
+	return Marshal(merged)
+}
+
+// Decodes the registered claims into RegisteredClaims and every other claim
+// into Extra.  Numbers in Extra are float64, whatever Parser.UseJSONNumber says.
+func (c *ExtendedClaims) UnmarshalJSON(data []byte) error {
+	var registered RegisteredClaims
+	if err := Unmarshal(data, &registered); err != nil {
+		return err
+	}
+	var extra map[string]interface{}
+	if err := Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	for _, name := range registeredClaimNames {
+		delete(extra, name)
+	}
+	c.RegisteredClaims, c.Extra = registered, extra
+	return nil
+}
+
+// Copies c along with its Audience and Extra, for Token.Clone
+func (c ExtendedClaims) deepCopy() ExtendedClaims {
+	c.RegisteredClaims = c.RegisteredClaims.deepCopy()
+	c.Extra = copyJSONMap(c.Extra)
+	return c
+}
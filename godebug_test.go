@@ -0,0 +1,9 @@
+//go:build go1.26
+// +build go1.26
+
+// Since Go 1.26 the crypto packages ignore a caller supplied random source
+// unless this setting is enabled.  The SignWithRand tests depend on it.
+
+//go:debug cryptocustomrand=1
+
+package jwt_test
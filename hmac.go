@@ -4,6 +4,8 @@ import (
 	"crypto"
 	"crypto/hmac"
 	"errors"
+	"hash"
+	"io"
 )
 
 // Implements the HMAC-SHA family of signing methods signing methods
@@ -45,8 +47,23 @@ func (m *SigningMethodHMAC) Alg() string {
 	return m.Name
 }
 
+// Implements HashFuncer
+func (m *SigningMethodHMAC) HashFunc() crypto.Hash {
+	return m.Hash
+}
+
+func (m *SigningMethodHMAC) checkVerificationKey(key interface{}) (string, bool) {
+	_, ok := key.([]byte)
+	return "[]byte", ok
+}
+
 // Verify the signature of HSXXX tokens.  Returns nil if the signature is valid.
+// The decoded signature is compared in constant time with hmac.Equal.
 func (m *SigningMethodHMAC) Verify(signingString, signature string, key interface{}) error {
+	if isNilKey(key) {
+		return ErrNilKey
+	}
+
 	// Verify the key is the right type
 	keyBytes, ok := key.([]byte)
 	if !ok {
@@ -80,16 +97,45 @@ func (m *SigningMethodHMAC) Verify(signingString, signature string, key interfac
 // Implements the Sign method from SigningMethod for this signing method.
 // Key must be []byte
 func (m *SigningMethodHMAC) Sign(signingString string, key interface{}) (string, error) {
-	if keyBytes, ok := key.([]byte); ok {
-		if !m.Hash.Available() {
-			return "", ErrHashUnavailable
-		}
+	hasher, err := m.signingHash(key)
+	if err != nil {
+		return "", err
+	}
+	hasher.Write([]byte(signingString))
+	return m.signDigest(nil, hasher.Sum(nil), key)
+}
 
-		hasher := hmac.New(m.Hash.New, keyBytes)
-		hasher.Write([]byte(signingString))
+func (m *SigningMethodHMAC) signingHash(key interface{}) (hash.Hash, error) {
+	if isNilKey(key) {
+		return nil, ErrNilKey
+	}
 
-		return EncodeSegment(hasher.Sum(nil)), nil
+	keyBytes, ok := key.([]byte)
+	if !ok {
+		return nil, ErrInvalidKeyType
+	}
+	if !m.Hash.Available() {
+		return nil, ErrHashUnavailable
 	}
+	return hmac.New(m.Hash.New, keyBytes), nil
+}
+
+// The HMAC is the signature
+func (m *SigningMethodHMAC) signDigest(_ io.Reader, digest []byte, _ interface{}) (string, error) {
+	return EncodeSegment(digest), nil
+}
+
+// Signs claims with HS256 using secret as the key.  This is HMAC only; use
+// NewWithClaims and SignedString for any other signing method.
+func SignWithSecret(claims Claims, secret string) (string, error) {
+	return NewWithClaims(SigningMethodHS256, claims).SignedString([]byte(secret))
+}
 
-	return "", ErrInvalidKeyType
+// Parses and validates a token signed with secret by SignWithSecret, or by
+// any HMAC method.  This is HMAC only: tokens using any other alg are
+// rejected, so a public key can never be mistaken for the secret.
+func ParseWithSecret(tokenString, secret string) (*Token, error) {
+	return Parse(tokenString, func(*Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, WithValidMethods([]string{"HS256", "HS384", "HS512"}))
 }
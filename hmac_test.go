@@ -2,6 +2,7 @@ package jwt_test
 
 import (
 	"github.com/dgrijalva/jwt-go"
+	"github.com/dgrijalva/jwt-go/test"
 	"io/ioutil"
 	"strings"
 	"testing"
@@ -78,6 +79,71 @@ func TestHMACSign(t *testing.T) {
 	}
 }
 
+func TestHMACCrossAlgorithm(t *testing.T) {
+	// An HS256 signature must not verify as HS384 or HS512
+	parts := strings.Split(hmacTestData[0].tokenString, ".")
+	for _, method := range []*jwt.SigningMethodHMAC{jwt.SigningMethodHS384, jwt.SigningMethodHS512} {
+		if err := method.Verify(strings.Join(parts[0:2], "."), parts[2], hmacTestKey); err == nil {
+			t.Errorf("[%v] HS256 signature passed validation", method.Alg())
+		}
+	}
+}
+
+func TestHMACVerifyLastByte(t *testing.T) {
+	parts := strings.Split(hmacTestData[0].tokenString, ".")
+
+	sig, err := jwt.DecodeSegment(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[len(sig)-1] ^= 1
+
+	err = jwt.SigningMethodHS256.Verify(strings.Join(parts[0:2], "."), jwt.EncodeSegment(sig), hmacTestKey)
+	if err != jwt.ErrSignatureInvalid {
+		t.Errorf("Expected ErrSignatureInvalid.  Got %v", err)
+	}
+}
+
+func TestHMACInvalidKeyType(t *testing.T) {
+	parts := strings.Split(hmacTestData[0].tokenString, ".")
+	signingString := strings.Join(parts[0:2], ".")
+
+	// An RSA key, or a string instead of []byte, must be rejected rather than panic
+	for _, key := range []interface{}{"not bytes", jwtTestDefaultKey} {
+		if _, err := jwt.SigningMethodHS256.Sign(signingString, key); err != jwt.ErrInvalidKeyType {
+			t.Errorf("[%T] Expected ErrInvalidKeyType while signing.  Got %v", key, err)
+		}
+		if err := jwt.SigningMethodHS256.Verify(signingString, parts[2], key); err != jwt.ErrInvalidKeyType {
+			t.Errorf("[%T] Expected ErrInvalidKeyType while verifying.  Got %v", key, err)
+		}
+	}
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	tokenString, err := jwt.SignWithSecret(jwt.MapClaims{"foo": "bar"}, "secret")
+	if err != nil {
+		t.Fatalf("Error signing token: %v", err)
+	}
+
+	token, err := jwt.ParseWithSecret(tokenString, "secret")
+	if err != nil || !token.Valid {
+		t.Fatalf("Error while verifying token: %v", err)
+	}
+	if token.Method != jwt.SigningMethodHS256 || token.Claims.(jwt.MapClaims)["foo"] != "bar" {
+		t.Errorf("Token doesn't match what was signed: %v %v", token.Header, token.Claims)
+	}
+
+	if _, err = jwt.ParseWithSecret(tokenString, "other secret"); err == nil {
+		t.Errorf("Expected a different secret to be rejected")
+	}
+
+	// Any other method is rejected before the secret is used as a key
+	rsaToken := test.MakeSampleToken(jwt.MapClaims{"foo": "bar"}, test.LoadRSAPrivateKeyFromDisk("test/sample_key"))
+	if _, err = jwt.ParseWithSecret(rsaToken, "secret"); err == nil || !strings.Contains(err.Error(), "signing method RS256 is invalid") {
+		t.Errorf("Expected RS256 to be rejected.  Got %v", err)
+	}
+}
+
 func BenchmarkHS256Signing(b *testing.B) {
 	benchmarkSigning(b, jwt.SigningMethodHS256, hmacTestKey)
 }
@@ -0,0 +1,171 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWKS errors
+var (
+	ErrJWKUnknownKeyID = errors.New("no key in the set matches the token's kid")
+	ErrJWKAlgMismatch  = errors.New("key does not match the token's signing method")
+)
+
+// A single JSON Web Key, as per RFC 7517.  Only the members needed for RSA
+// and EC public keys are decoded.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Decodes the RSA and EC public keys in a JWK Set document, such as an OIDC
+// provider's jwks_uri, into a map keyed by kid.  A key without a kid is stored
+// under the empty string; RFC 7517 allows several, and only the first is kept.
+// Two keys with the same non-empty kid are an error.  Keys of other types, and
+// keys whose use is not "sig", are skipped, as RFC 7517 advises.  Each value
+// holds the *rsa.PublicKey or *ecdsa.PublicKey along with the alg the key
+// declares, ready for KeyFuncFromJWKS or a Keyfunc of your own.
+func ParseJWKS(data []byte) (map[string]*JWK, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*JWK, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key interface{}
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", jwk.Kid, err)
+		}
+
+		if _, ok := keys[jwk.Kid]; ok {
+			if jwk.Kid == "" {
+				continue
+			}
+			return nil, fmt.Errorf("key %q appears more than once", jwk.Kid)
+		}
+		keys[jwk.Kid] = &JWK{Key: key, Alg: jwk.Alg}
+	}
+	return keys, nil
+}
+
+// A public key from a JWK Set together with the alg the key declares, if any
+type JWK struct {
+	Key interface{} // An *rsa.PublicKey or *ecdsa.PublicKey
+	Alg string      // Empty if the key doesn't declare one
+}
+
+// Returns a Keyfunc that picks the key in keys, as returned by ParseJWKS,
+// matching the token's kid header.  It fails with ErrJWKUnknownKeyID if there
+// is none, and with ErrJWKAlgMismatch if the key can't be used with the
+// token's signing method, such as an EC key for an RS256 token, or if the key
+// declares an alg that differs from the token's.  It returns the raw key.
+func KeyFuncFromJWKS(keys map[string]*JWK) Keyfunc {
+	return func(token *Token) (interface{}, error) {
+		jwk, ok := keys[token.KeyID()]
+		if !ok || jwk == nil {
+			return nil, ErrJWKUnknownKeyID
+		}
+		if jwk.Alg != "" && jwk.Alg != token.Method.Alg() {
+			return nil, ErrJWKAlgMismatch
+		}
+		if !publicKeyFitsMethod(token.Method, jwk.Key) {
+			return nil, ErrJWKAlgMismatch
+		}
+		return jwk.Key, nil
+	}
+}
+
+// Reports whether key is the kind of public key method verifies with
+func publicKeyFitsMethod(method SigningMethod, key interface{}) bool {
+	switch m := method.(type) {
+	case *SigningMethodRSA, *SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *SigningMethodECDSA:
+		k, ok := key.(*ecdsa.PublicKey)
+		return ok && k.Curve.Params().BitSize == m.CurveBits
+	}
+	return false
+}
+
+func (jwk *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeJWKInt(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid n: %v", err)
+	}
+	e, err := decodeJWKInt(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid e: %v", err)
+	}
+	if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid e: out of range")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (jwk *jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+
+	x, err := decodeJWKInt(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x: %v", err)
+	}
+	y, err := decodeJWKInt(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y: %v", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// Decodes a base64url encoded big-endian unsigned integer
+func decodeJWKInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("missing")
+	}
+	b, err := DecodeSegment(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
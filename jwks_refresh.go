@@ -0,0 +1,79 @@
+package jwt
+
+import (
+	"sync"
+	"time"
+)
+
+// Like KeyFuncFromJWKS, but the keys come from fetch, which would typically
+// download and ParseJWKS a provider's jwks_uri.  When a token's kid isn't in
+// the keys, as happens after the provider rotates them, fetch is called again
+// and the lookup retried once.  Concurrent misses share a single call, and
+// misses refresh at most once every minInterval, so a flood of tokens with
+// unknown kids can't hammer the key source.  fetch is called once up front,
+// which counts as a refresh; its error is returned if it fails.  The Keyfunc
+// is safe for concurrent use.
+func RefreshingKeyFuncFromJWKS(fetch func() (map[string]*JWK, error), minInterval time.Duration) (Keyfunc, error) {
+	keys, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	r := &refreshingJWKS{fetch: fetch, minInterval: minInterval, keys: keys, lastRefresh: TimeFunc()}
+	return r.keyFunc, nil
+}
+
+type refreshingJWKS struct {
+	fetch       func() (map[string]*JWK, error)
+	minInterval time.Duration
+
+	mu         sync.Mutex // Guards keys and generation
+	keys       map[string]*JWK
+	generation uint64
+
+	refreshMu   sync.Mutex // Held while refreshing; guards lastRefresh
+	lastRefresh time.Time
+}
+
+func (r *refreshingJWKS) keyFunc(token *Token) (interface{}, error) {
+	r.mu.Lock()
+	keys, generation := r.keys, r.generation
+	r.mu.Unlock()
+
+	key, err := KeyFuncFromJWKS(keys)(token)
+	if err != ErrJWKUnknownKeyID {
+		return key, err
+	}
+
+	if keys, err = r.refresh(generation); err != nil {
+		return nil, err
+	}
+	return KeyFuncFromJWKS(keys)(token)
+}
+
+// Fetches the keys again, unless they have changed since generation was seen
+// or the last refresh was under minInterval ago.  Returns the current keys.
+func (r *refreshingJWKS) refresh(seen uint64) (map[string]*JWK, error) {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	r.mu.Lock()
+	keys, generation := r.keys, r.generation
+	r.mu.Unlock()
+
+	now := TimeFunc()
+	if generation != seen || now.Sub(r.lastRefresh) < r.minInterval {
+		return keys, nil
+	}
+	r.lastRefresh = now
+
+	keys, err := r.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.generation++
+	r.mu.Unlock()
+	return keys, nil
+}
@@ -0,0 +1,120 @@
+package jwt_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/dgrijalva/jwt-go/test"
+)
+
+func TestRefreshingKeyFuncFromJWKS(t *testing.T) {
+	rsaKey := test.LoadRSAPrivateKeyFromDisk("test/sample_key")
+	before := map[string]*jwt.JWK{"rsa-0": {Key: test.LoadRSAPublicKeyFromDisk("test/sample_key.pub")}}
+	after := map[string]*jwt.JWK{"rsa-1": {Key: &rsaKey.PublicKey}}
+
+	var fetches int32
+	current := before
+	var keyFunc jwt.Keyfunc
+	var err error
+	start := time.Unix(1000, 0)
+	at(start, func() {
+		keyFunc, err = jwt.RefreshingKeyFuncFromJWKS(func() (map[string]*jwt.JWK, error) {
+			atomic.AddInt32(&fetches, 1)
+			// Slow enough that the concurrent misses overlap
+			time.Sleep(10 * time.Millisecond)
+			return current, nil
+		}, time.Minute)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"foo": "bar"})
+		token.Header["kid"] = kid
+		s, err := token.SignedString(rsaKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// The provider rotates to a new kid, but the initial fetch counts as a
+	// refresh, so a miss within minInterval of it doesn't fetch again
+	current = after
+	rotated := sign("rsa-1")
+	at(start.Add(30*time.Second), func() {
+		_, err = jwt.Parse(rotated, keyFunc)
+	})
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Inner != jwt.ErrJWKUnknownKeyID || fetches != 1 {
+		t.Errorf("Expected no refresh within minInterval of the initial fetch.  Got %v after %d fetches", err, fetches)
+	}
+
+	now := start.Add(2 * time.Minute)
+	at(now, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := jwt.Parse(rotated, keyFunc); err != nil {
+					t.Errorf("Error while verifying token after rotation: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+	if fetches != 2 {
+		t.Errorf("Expected one refresh for the concurrent misses, got %d", fetches-1)
+	}
+
+	// An unknown kid within minInterval of the refresh doesn't fetch again
+	unknown := sign("rsa-2")
+	at(now.Add(30*time.Second), func() {
+		_, err = jwt.Parse(unknown, keyFunc)
+	})
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Inner != jwt.ErrJWKUnknownKeyID {
+		t.Errorf("Expected ErrJWKUnknownKeyID.  Got %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected no refresh within minInterval, got %d fetches", fetches)
+	}
+
+	// After minInterval it does
+	at(now.Add(2*time.Minute), func() {
+		_, err = jwt.Parse(unknown, keyFunc)
+	})
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Inner != jwt.ErrJWKUnknownKeyID {
+		t.Errorf("Expected ErrJWKUnknownKeyID.  Got %v", err)
+	}
+	if fetches != 3 {
+		t.Errorf("Expected a refresh after minInterval, got %d fetches", fetches)
+	}
+}
+
+func TestRefreshingKeyFuncFromJWKS_FetchError(t *testing.T) {
+	fetchErr := errors.New("jwks unavailable")
+	if _, err := jwt.RefreshingKeyFuncFromJWKS(func() (map[string]*jwt.JWK, error) {
+		return nil, fetchErr
+	}, time.Minute); err != fetchErr {
+		t.Errorf("Expected the initial fetch error.  Got %v", err)
+	}
+
+	calls := 0
+	keyFunc, _ := jwt.RefreshingKeyFuncFromJWKS(func() (map[string]*jwt.JWK, error) {
+		if calls++; calls > 1 {
+			return nil, fetchErr
+		}
+		return map[string]*jwt.JWK{}, nil
+	}, 0)
+
+	tokenString := test.MakeSampleToken(jwt.MapClaims{"foo": "bar"}, test.LoadRSAPrivateKeyFromDisk("test/sample_key"))
+	_, err := jwt.Parse(tokenString, keyFunc)
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Inner != fetchErr {
+		t.Errorf("Expected the refresh error.  Got %v", err)
+	}
+}
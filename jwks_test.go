@@ -0,0 +1,189 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/dgrijalva/jwt-go/test"
+)
+
+func loadJWKS(t *testing.T) map[string]*jwt.JWK {
+	data, err := ioutil.ReadFile("test/jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := jwt.ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("Error parsing JWKS: %v", err)
+	}
+	return keys
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys := loadJWKS(t)
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 keys, got %d", len(keys))
+	}
+
+	rsaKey, ok := keys["rsa-1"].Key.(*rsa.PublicKey)
+	if !ok || rsaKey.N.Cmp(test.LoadRSAPublicKeyFromDisk("test/sample_key.pub").N) != 0 || keys["rsa-1"].Alg != "" {
+		t.Errorf("RSA key doesn't match test/sample_key.pub: %v", keys["rsa-1"])
+	}
+
+	ecData, _ := ioutil.ReadFile("test/ec256-public.pem")
+	expected, _ := jwt.ParseECPublicKeyFromPEM(ecData)
+	ecKey, ok := keys["ec-1"].Key.(*ecdsa.PublicKey)
+	if !ok || ecKey.X.Cmp(expected.X) != 0 || ecKey.Y.Cmp(expected.Y) != 0 {
+		t.Errorf("EC key doesn't match test/ec256-public.pem: %v", keys["ec-1"])
+	}
+}
+
+func TestParseJWKS_Invalid(t *testing.T) {
+	var invalidJWKSTestData = []struct {
+		name  string
+		jwks  string
+		keys  int
+		error string
+	}{
+		{"not json", `{"keys":`, 0, "unexpected end of JSON input"},
+		{"skips other key types", `{"keys":[{"kty":"oct","kid":"h","k":"c2VjcmV0"}]}`, 0, ""},
+		{"skips encryption keys", `{"keys":[{"kty":"RSA","kid":"r","use":"enc","n":"AQAB","e":"AQAB"}]}`, 0, ""},
+		{"missing modulus", `{"keys":[{"kty":"RSA","kid":"r","e":"AQAB"}]}`, 0, `key "r": invalid n: missing`},
+		{"bad exponent", `{"keys":[{"kty":"RSA","kid":"r","n":"AQAB","e":"AQ"}]}`, 0, `key "r": invalid e: out of range`},
+		{"unknown curve", `{"keys":[{"kty":"EC","kid":"e","crv":"P-224","x":"AQ","y":"AQ"}]}`, 0, `key "e": unsupported curve "P-224"`},
+		{"point off curve", `{"keys":[{"kty":"EC","kid":"e","crv":"P-256","x":"AQ","y":"AQ"}]}`, 0, `key "e": point is not on the curve`},
+		{"several keys without a kid", `{"keys":[{"kty":"RSA","alg":"RS256","n":"AQAB","e":"AQAB"},{"kty":"RSA","alg":"PS256","n":"AQAB","e":"AQAB"}]}`, 1, ""},
+		{"duplicate kid", `{"keys":[{"kty":"RSA","kid":"r","n":"AQAB","e":"AQAB"},{"kty":"RSA","kid":"r","n":"AQAB","e":"AQAB"}]}`, 0, `key "r" appears more than once`},
+	}
+
+	for _, data := range invalidJWKSTestData {
+		keys, err := jwt.ParseJWKS([]byte(data.jwks))
+		if data.error == "" {
+			if err != nil || len(keys) != data.keys {
+				t.Errorf("[%v] Expected %d keys, got %v (%v)", data.name, data.keys, keys, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != data.error {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, data.error)
+		}
+	}
+}
+
+func TestKeyFuncFromJWKS(t *testing.T) {
+	keyFunc := jwt.KeyFuncFromJWKS(loadJWKS(t))
+
+	rsaKey := test.LoadRSAPrivateKeyFromDisk("test/sample_key")
+	ecData, _ := ioutil.ReadFile("test/ec256-private.pem")
+	ecKey, _ := jwt.ParseECPrivateKeyFromPEM(ecData)
+	ec384Data, _ := ioutil.ReadFile("test/ec384-private.pem")
+	ec384Key, _ := jwt.ParseECPrivateKeyFromPEM(ec384Data)
+
+	var jwksTestData = []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    interface{}
+		err    error
+	}{
+		{"rsa", jwt.SigningMethodRS256, "rsa-1", rsaKey, nil},
+		{"rsa pss", jwt.SigningMethodPS256, "rsa-1", rsaKey, nil},
+		{"ecdsa", jwt.SigningMethodES256, "ec-1", ecKey, nil},
+		{"unknown kid", jwt.SigningMethodRS256, "rsa-2", rsaKey, jwt.ErrJWKUnknownKeyID},
+		{"missing kid", jwt.SigningMethodRS256, "", rsaKey, jwt.ErrJWKUnknownKeyID},
+		{"ec key for rsa method", jwt.SigningMethodES256, "rsa-1", ecKey, jwt.ErrJWKAlgMismatch},
+		{"wrong curve", jwt.SigningMethodES384, "ec-1", ec384Key, jwt.ErrJWKAlgMismatch},
+		{"hmac", jwt.SigningMethodHS256, "rsa-1", hmacTestKey, jwt.ErrJWKAlgMismatch},
+	}
+
+	for _, data := range jwksTestData {
+		token := jwt.NewWithClaims(data.method, jwt.MapClaims{"foo": "bar"})
+		if data.kid != "" {
+			token.Header["kid"] = data.kid
+		}
+		tokenString, err := token.SignedString(data.key)
+		if err != nil {
+			t.Fatalf("[%v] Error signing token: %v", data.name, err)
+		}
+
+		token, err = jwt.Parse(tokenString, keyFunc)
+		if data.err == nil {
+			if err != nil || !token.Valid {
+				t.Errorf("[%v] Error while verifying token: %v", data.name, err)
+			}
+			continue
+		}
+		if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorUnverifiable || ve.Inner != data.err {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, data.err)
+		}
+	}
+}
+
+func TestKeyFuncFromJWKS_Alg(t *testing.T) {
+	data, _ := ioutil.ReadFile("test/jwks.json")
+	keys, err := jwt.ParseJWKS([]byte(strings.Replace(string(data), `"kid": "rsa-1",`, `"kid": "rsa-1", "alg": "RS256",`, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwk := keys["rsa-1"]; jwk.Alg != "RS256" {
+		t.Fatalf("Expected a key with alg RS256, got %v", jwk)
+	}
+	keyFunc := jwt.KeyFuncFromJWKS(keys)
+	rsaKey := test.LoadRSAPrivateKeyFromDisk("test/sample_key")
+
+	var algTestData = []struct {
+		name   string
+		method jwt.SigningMethod
+		valid  bool
+	}{
+		{"matching alg", jwt.SigningMethodRS256, true},
+		{"same key type, other alg", jwt.SigningMethodPS256, false},
+		{"same key type, other hash", jwt.SigningMethodRS512, false},
+	}
+
+	for _, data := range algTestData {
+		token := jwt.NewWithClaims(data.method, jwt.MapClaims{"foo": "bar"})
+		token.Header["kid"] = "rsa-1"
+		tokenString, err := token.SignedString(rsaKey)
+		if err != nil {
+			t.Fatalf("[%v] Error signing token: %v", data.name, err)
+		}
+
+		_, err = jwt.Parse(tokenString, keyFunc)
+		if data.valid {
+			if err != nil {
+				t.Errorf("[%v] Error while verifying token: %v", data.name, err)
+			}
+			continue
+		}
+		if ve, ok := err.(*jwt.ValidationError); !ok || ve.Inner != jwt.ErrJWKAlgMismatch {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, jwt.ErrJWKAlgMismatch)
+		}
+	}
+}
+
+func TestKeyFuncFromJWKS_NoKid(t *testing.T) {
+	data, _ := ioutil.ReadFile("test/jwks.json")
+	keys, err := jwt.ParseJWKS([]byte(strings.Replace(string(data), `"kid": "rsa-1",`, "", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenString := test.MakeSampleToken(jwt.MapClaims{"foo": "bar"}, test.LoadRSAPrivateKeyFromDisk("test/sample_key"))
+	if _, err = jwt.Parse(tokenString, jwt.KeyFuncFromJWKS(keys)); err != nil {
+		t.Errorf("Expected the key without a kid to verify a token without one: %v", err)
+	}
+
+	// With the EC key's kid removed too, the first key without one is kept
+	keys, err = jwt.ParseJWKS([]byte(strings.Replace(strings.Replace(string(data), `"kid": "rsa-1",`, "", 1), `"kid": "ec-1",`, "", 1)))
+	if err != nil {
+		t.Fatalf("Error parsing JWKS with two keys without a kid: %v", err)
+	}
+	if _, err = jwt.Parse(tokenString, jwt.KeyFuncFromJWKS(keys)); err != nil {
+		t.Errorf("Expected the first key without a kid to be kept: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The most entries a caching Keyfunc holds.  Since kid comes from the token,
+// an attacker could otherwise grow the cache without bound.
+const maxCachedKeys = 1024
+
+// The most errors a caching Keyfunc holds.  They are kept apart from the keys,
+// so tokens with made up kids can't crowd out the keys for real ones.
+const maxCachedErrors = 64
+
+// Wraps inner so that the key it returns for a kid and alg is reused for ttl
+// rather than looked up on every parse.  Tokens without a kid are cached by
+// their whole header.  Errors are not cached; see CachingKeyFuncWithNegativeTTL.
+// The returned Keyfunc is safe for concurrent use.  Expiry follows TimeFunc.
+//
+// A cache hit skips inner, so inner must pick its key from kid and alg alone.
+// If it also looks at other fields, such as a Keyfunc serving several issuers
+// that reads iss, wrap a separate Keyfunc for each issuer instead.
+func CachingKeyFunc(inner Keyfunc, ttl time.Duration) Keyfunc {
+	return CachingKeyFuncWithNegativeTTL(inner, ttl, 0)
+}
+
+// Like CachingKeyFunc, but an error from inner is also cached, for
+// negativeTTL, so a failing key source isn't called on every parse.  This is
+// usually shorter than ttl.  A negativeTTL of zero disables it.  Errors are
+// cached by kid and alg, like keys, but never replace a cached key and have
+// their own, smaller, limit.
+func CachingKeyFuncWithNegativeTTL(inner Keyfunc, ttl, negativeTTL time.Duration) Keyfunc {
+	c := &keyCache{
+		inner:       inner,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		entries:     make(map[string]keyCacheEntry),
+		errors:      make(map[string]keyCacheEntry),
+	}
+	return c.keyFunc
+}
+
+type keyCache struct {
+	inner            Keyfunc
+	ttl, negativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]keyCacheEntry // Keys, by keyCacheID
+	errors  map[string]keyCacheEntry // Errors, by keyCacheID
+}
+
+type keyCacheEntry struct {
+	key     interface{}
+	err     error
+	expires time.Time
+}
+
+func (c *keyCache) keyFunc(token *Token) (interface{}, error) {
+	id, err := keyCacheID(token)
+	if err != nil {
+		return c.inner(token)
+	}
+
+	if entry, ok := c.lookup(c.entries, id); ok {
+		return entry.key, nil
+	}
+	if entry, ok := c.lookup(c.errors, id); ok {
+		return nil, entry.err
+	}
+
+	key, err := c.inner(token)
+	if err == nil && c.ttl > 0 {
+		c.store(c.entries, maxCachedKeys, id, keyCacheEntry{key, nil, TimeFunc().Add(c.ttl)})
+	} else if err != nil && c.negativeTTL > 0 {
+		c.store(c.errors, maxCachedErrors, id, keyCacheEntry{nil, err, TimeFunc().Add(c.negativeTTL)})
+	}
+	return key, err
+}
+
+// Returns the entry for id in entries, if there is one that hasn't expired
+func (c *keyCache) lookup(entries map[string]keyCacheEntry, id string) (keyCacheEntry, bool) {
+	c.mu.Lock()
+	entry, ok := entries[id]
+	c.mu.Unlock()
+	return entry, ok && TimeFunc().Before(entry.expires)
+}
+
+// Stores entry in entries, unless they hold limit entries that haven't expired
+func (c *keyCache) store(entries map[string]keyCacheEntry, limit int, id string, entry keyCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := entries[id]; !ok && len(entries) >= limit {
+		now := TimeFunc()
+		for k, e := range entries {
+			if !now.Before(e.expires) {
+				delete(entries, k)
+			}
+		}
+		if len(entries) >= limit {
+			return
+		}
+	}
+	entries[id] = entry
+}
+
+// Returns the cache key for token: its alg and kid, or else its whole header
+func keyCacheID(token *Token) (string, error) {
+	if kid := token.KeyID(); kid != "" {
+		alg, _ := token.Header["alg"].(string)
+		return "kid:" + strconv.Quote(alg) + ":" + kid, nil
+	}
+	header, err := json.Marshal(token.Header)
+	if err != nil {
+		return "", err
+	}
+	return "header:" + string(header), nil
+}
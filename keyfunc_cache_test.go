@@ -0,0 +1,193 @@
+package jwt_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestCachingKeyFunc(t *testing.T) {
+	var calls int32
+	keyFunc := jwt.CachingKeyFunc(func(token *jwt.Token) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return hmacTestKey, nil
+	}, time.Minute)
+
+	sign := func(kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(hmacTestKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	withKid, withoutKid := sign("key-1"), sign("")
+
+	parse := func(tokenString string) {
+		if _, err := jwt.Parse(tokenString, keyFunc); err != nil {
+			t.Errorf("Error while verifying token: %v", err)
+		}
+	}
+
+	now := time.Unix(1000, 0)
+	at(now, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				parse(withKid)
+			}()
+		}
+		wg.Wait()
+		calls = 0
+		for i := 0; i < 5; i++ {
+			parse(withKid)
+		}
+	})
+	if calls != 0 {
+		t.Errorf("Expected cached key to be reused within the TTL, inner called %d times", calls)
+	}
+
+	at(now.Add(30*time.Second), func() {
+		parse(withoutKid)
+		parse(withoutKid)
+	})
+	if calls != 1 {
+		t.Errorf("Expected a token without kid to be cached by header, inner called %d times", calls)
+	}
+
+	at(now.Add(61*time.Second), func() {
+		parse(withKid)
+	})
+	if calls != 2 {
+		t.Errorf("Expected the entry to expire after the TTL, inner called %d times", calls)
+	}
+}
+
+func TestCachingKeyFuncWithNegativeTTL(t *testing.T) {
+	var calls int32
+	errUnavailable := errors.New("key source unavailable")
+	inner := func(token *jwt.Token) (interface{}, error) {
+		calls++
+		return nil, errUnavailable
+	}
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"}).SignedString(hmacTestKey)
+
+	var negativeTestData = []struct {
+		name        string
+		keyFunc     jwt.Keyfunc
+		calls       int32
+		afterExpiry int32
+	}{
+		{"errors not cached", jwt.CachingKeyFunc(inner, time.Minute), 3, 4},
+		{"errors cached", jwt.CachingKeyFuncWithNegativeTTL(inner, time.Minute, 5*time.Second), 1, 2},
+	}
+
+	for _, data := range negativeTestData {
+		calls = 0
+		now := time.Unix(1000, 0)
+		at(now, func() {
+			for i := 0; i < 3; i++ {
+				_, err := jwt.Parse(tokenString, data.keyFunc)
+				if ve, ok := err.(*jwt.ValidationError); !ok || ve.Inner != errUnavailable {
+					t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, errUnavailable)
+				}
+			}
+		})
+		if calls != data.calls {
+			t.Errorf("[%v] Expected inner to be called %d times, was %d", data.name, data.calls, calls)
+		}
+
+		at(now.Add(6*time.Second), func() {
+			jwt.Parse(tokenString, data.keyFunc)
+		})
+		if calls != data.afterExpiry {
+			t.Errorf("[%v] Expected inner to be called %d times after expiry, was %d", data.name, data.afterExpiry, calls)
+		}
+	}
+}
+
+func TestCachingKeyFuncKeysOnAlg(t *testing.T) {
+	var calls int32
+	keyFunc := jwt.CachingKeyFunc(func(token *jwt.Token) (interface{}, error) {
+		calls++
+		if token.Method.Alg() != "HS256" {
+			return nil, errors.New("key is for HS256")
+		}
+		return hmacTestKey, nil
+	}, time.Minute)
+
+	sign := func(method jwt.SigningMethod) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"foo": "bar"})
+		token.Header["kid"] = "key-1"
+		s, err := token.SignedString(hmacTestKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	if _, err := jwt.Parse(sign(jwt.SigningMethodHS256), keyFunc); err != nil {
+		t.Errorf("Error while verifying token: %v", err)
+	}
+	if _, err := jwt.Parse(sign(jwt.SigningMethodHS512), keyFunc); err == nil {
+		t.Errorf("Expected the cached key not to be used for another alg")
+	}
+	if calls != 2 {
+		t.Errorf("Expected inner to be called for each alg, was called %d times", calls)
+	}
+}
+
+func TestCachingKeyFuncWithNegativeTTLUnknownKids(t *testing.T) {
+	calls := make(map[string]int)
+	errUnknown := errors.New("unknown kid")
+	keyFunc := jwt.CachingKeyFuncWithNegativeTTL(func(token *jwt.Token) (interface{}, error) {
+		calls[token.KeyID()]++
+		if token.KeyID() != "good" {
+			return nil, errUnknown
+		}
+		return hmacTestKey, nil
+	}, time.Minute, time.Minute)
+
+	sign := func(kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(hmacTestKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	at(time.Unix(1000, 0), func() {
+		// Distinct tokens for one bad kid share its cached error
+		for i := 0; i < 5; i++ {
+			jwt.Parse(sign("bad", jwt.MapClaims{"n": i}), keyFunc)
+		}
+		if calls["bad"] != 1 {
+			t.Errorf("Expected inner to be called once for the bad kid, was %d", calls["bad"])
+		}
+
+		// Many bad kids, more than the cache holds, don't stop the good one being cached
+		for i := 0; i < 2000; i++ {
+			jwt.Parse(sign(fmt.Sprintf("bad-%d", i), jwt.MapClaims{"n": i}), keyFunc)
+		}
+		for i := 0; i < 3; i++ {
+			if _, err := jwt.Parse(sign("good", jwt.MapClaims{"n": i}), keyFunc); err != nil {
+				t.Errorf("Error while verifying token: %v", err)
+			}
+		}
+		if calls["good"] != 1 {
+			t.Errorf("Expected the good kid to be cached, inner called %d times", calls["good"])
+		}
+	})
+}
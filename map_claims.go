@@ -3,44 +3,96 @@ package jwt
 import (
 	"encoding/json"
 	"errors"
-	// "fmt"
+	"fmt"
+	"strconv"
+	"time"
 )
 
 // Claims type that uses the map[string]interface{} for JSON decoding
 // This is the default claims type if you don't supply one
 type MapClaims map[string]interface{}
 
+// Returns the named claim if it is a string.
+func (m MapClaims) GetString(key string) (string, bool) {
+	v, ok := m[key].(string)
+	return v, ok
+}
+
+// Returns the named claim if it is numeric, whether it was decoded as a
+// float64 or, with Parser.UseJSONNumber, as a json.Number.  Fractional
+// values are truncated.
+func (m MapClaims) GetInt64(key string) (int64, bool) {
+	return toInt64(m[key])
+}
+
+// Returns the named claim as a time, interpreting it as seconds since the
+// Unix epoch like exp, iat and nbf.
+func (m MapClaims) GetTime(key string) (time.Time, bool) {
+	v, ok := m.GetInt64(key)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(v, 0), true
+}
+
 // Compares the aud claim against cmp.
 // If required is false, this method will return true if the value matches or is unset
+// The aud claim may be a single string or an array of strings.
 func (m MapClaims) VerifyAudience(cmp string, req bool) bool {
-	aud, _ := m["aud"].(string)
+	aud, ok := audience(m["aud"])
+	if !ok {
+		return false
+	}
 	return verifyAud(aud, cmp, req)
 }
 
+// Reports whether the aud claim contains every one of required, for tokens
+// that must be valid for a whole set of audiences.  VerifyAudience passes if
+// any one matches.  The aud claim may be a single string or an array of
+// strings, and is always required, even if required is empty.
+func (m MapClaims) VerifyAllAudiences(required []string) bool {
+	aud, ok := audience(m["aud"])
+	if !ok {
+		return false
+	}
+	result := false
+	for _, a := range aud {
+		if a != "" {
+			result = true
+		}
+	}
+	for _, cmp := range required {
+		if !verifyAud(aud, cmp, true) {
+			result = false
+		}
+	}
+	return result
+}
+
 // Compares the exp claim against cmp.
 // If required is false, this method will return true if the value matches or is unset
 func (m MapClaims) VerifyExpiresAt(cmp int64, req bool) bool {
-	switch exp := m["exp"].(type) {
-	case float64:
-		return verifyExp(int64(exp), cmp, req)
-	case json.Number:
-		v, _ := exp.Int64()
-		return verifyExp(v, cmp, req)
+	v, present, err := numericDate(m["exp"])
+	if err != nil {
+		return false
 	}
-	return req == false
+	if !present {
+		return req == false
+	}
+	return verifyExp(v, cmp, req)
 }
 
 // Compares the iat claim against cmp.
 // If required is false, this method will return true if the value matches or is unset
 func (m MapClaims) VerifyIssuedAt(cmp int64, req bool) bool {
-	switch iat := m["iat"].(type) {
-	case float64:
-		return verifyIat(int64(iat), cmp, req)
-	case json.Number:
-		v, _ := iat.Int64()
-		return verifyIat(v, cmp, req)
+	v, present, err := numericDate(m["iat"])
+	if err != nil {
+		return false
 	}
-	return req == false
+	if !present {
+		return req == false
+	}
+	return verifyIat(v, cmp, req)
 }
 
 // Compares the iss claim against cmp.
@@ -53,35 +105,57 @@ func (m MapClaims) VerifyIssuer(cmp string, req bool) bool {
 // Compares the nbf claim against cmp.
 // If required is false, this method will return true if the value matches or is unset
 func (m MapClaims) VerifyNotBefore(cmp int64, req bool) bool {
-	switch nbf := m["nbf"].(type) {
-	case float64:
-		return verifyNbf(int64(nbf), cmp, req)
-	case json.Number:
-		v, _ := nbf.Int64()
-		return verifyNbf(v, cmp, req)
+	v, present, err := numericDate(m["nbf"])
+	if err != nil {
+		return false
+	}
+	if !present {
+		return req == false
 	}
-	return req == false
+	return verifyNbf(v, cmp, req)
+}
+
+// Zero if exp is missing or isn't a valid numeric date
+func (m MapClaims) expiresAt() int64 {
+	exp, _, _ := numericDate(m["exp"])
+	return exp
+}
+
+// Stored as float64, the type decoding from JSON gives
+func (m MapClaims) setTimeClaim(name string, unix int64) {
+	m[name] = float64(unix)
 }
 
 // Validates time based claims "exp, iat, nbf".
-// There is no accounting for clock skew.
+// There is no accounting for clock skew.  Use Parser.Leeway to allow for it.
 // As well, if any of the above claims are not in the token, it will still
 // be considered a valid claim.
 func (m MapClaims) Valid() error {
+	return m.valid(validator{})
+}
+
+func (m MapClaims) valid(v validator) error {
+	// A time claim that can't be read must not be mistaken for an absent one
+	for _, name := range []string{"exp", "iat", "nbf"} {
+		if _, _, err := numericDate(m[name]); err != nil {
+			return &ValidationError{Inner: fmt.Errorf("%v claim is not a valid number", name), Errors: ValidationErrorMalformed}
+		}
+	}
+
 	vErr := new(ValidationError)
 	now := TimeFunc().Unix()
 
-	if m.VerifyExpiresAt(now, false) == false {
+	if m.VerifyExpiresAt(now-leewaySeconds(v.expLeeway), false) == false {
 		vErr.Inner = errors.New("Token is expired")
 		vErr.Errors |= ValidationErrorExpired
 	}
 
-	if m.VerifyIssuedAt(now, false) == false {
+	if m.VerifyIssuedAt(now+leewaySeconds(v.iatLeeway), false) == false {
 		vErr.Inner = errors.New("Token used before issued")
 		vErr.Errors |= ValidationErrorIssuedAt
 	}
 
-	if m.VerifyNotBefore(now, false) == false {
+	if m.VerifyNotBefore(now+leewaySeconds(v.nbfLeeway), false) == false {
 		vErr.Inner = errors.New("Token is not valid yet")
 		vErr.Errors |= ValidationErrorNotValidYet
 	}
@@ -92,3 +166,40 @@ func (m MapClaims) Valid() error {
 
 	return vErr
 }
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int64(v), true
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, true
+		}
+		if f, err := v.Float64(); err == nil {
+			return int64(f), true
+		}
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// Reads a time claim such as exp.  Some non-conformant issuers encode these
+// as strings, which are accepted as long as they hold an integer.  A value
+// that is present but can't be read is an error.
+func numericDate(value interface{}) (v int64, present bool, err error) {
+	if value == nil {
+		return 0, false, nil
+	}
+	if v, ok := toInt64(value); ok {
+		return v, true, nil
+	}
+	if s, ok := value.(string); ok {
+		if v, err = strconv.ParseInt(s, 10, 64); err == nil {
+			return v, true, nil
+		}
+	}
+	return 0, true, fmt.Errorf("invalid numeric date %v", value)
+}
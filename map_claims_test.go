@@ -0,0 +1,180 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var mapClaimsAudienceTestData = []struct {
+	name     string
+	claims   jwt.MapClaims
+	cmp      string
+	required bool
+	expected bool
+}{
+	{"string match", jwt.MapClaims{"aud": "foo"}, "foo", true, true},
+	{"string mismatch", jwt.MapClaims{"aud": "foo"}, "bar", true, false},
+	{"array match", jwt.MapClaims{"aud": []interface{}{"foo", "bar"}}, "bar", true, true},
+	{"array mismatch", jwt.MapClaims{"aud": []interface{}{"foo", "bar"}}, "baz", true, false},
+	{"string slice match", jwt.MapClaims{"aud": []string{"foo", "bar"}}, "foo", true, true},
+	{"array with non-string", jwt.MapClaims{"aud": []interface{}{"foo", 1}}, "foo", false, false},
+	{"missing, not required", jwt.MapClaims{}, "foo", false, true},
+	{"missing, required", jwt.MapClaims{}, "foo", true, false},
+	{"empty array, required", jwt.MapClaims{"aud": []interface{}{}}, "foo", true, false},
+}
+
+func TestMapClaims_VerifyAudience(t *testing.T) {
+	for _, data := range mapClaimsAudienceTestData {
+		if got := data.claims.VerifyAudience(data.cmp, data.required); got != data.expected {
+			t.Errorf("[%v] Expected %v.  Got %v", data.name, data.expected, got)
+		}
+	}
+}
+
+func TestMapClaims_VerifyAllAudiences(t *testing.T) {
+	var allAudiencesTestData = []struct {
+		name     string
+		claims   jwt.MapClaims
+		required []string
+		expected bool
+	}{
+		{"full match", jwt.MapClaims{"aud": []interface{}{"api", "billing"}}, []string{"api", "billing"}, true},
+		{"different order", jwt.MapClaims{"aud": []interface{}{"billing", "api"}}, []string{"api", "billing"}, true},
+		{"partial match", jwt.MapClaims{"aud": []interface{}{"api"}}, []string{"api", "billing"}, false},
+		{"superset", jwt.MapClaims{"aud": []interface{}{"api", "billing", "admin"}}, []string{"api", "billing"}, true},
+		{"no match", jwt.MapClaims{"aud": []interface{}{"admin"}}, []string{"api"}, false},
+		{"string aud", jwt.MapClaims{"aud": "api"}, []string{"api"}, true},
+		{"string aud, partial", jwt.MapClaims{"aud": "api"}, []string{"api", "billing"}, false},
+		{"string slice aud", jwt.MapClaims{"aud": []string{"api", "billing"}}, []string{"billing", "api"}, true},
+		{"missing aud", jwt.MapClaims{}, []string{"api"}, false},
+		{"missing aud, none required", jwt.MapClaims{}, nil, false},
+		{"empty aud, none required", jwt.MapClaims{"aud": []interface{}{}}, nil, false},
+		{"present aud, none required", jwt.MapClaims{"aud": "api"}, nil, true},
+		{"non-string aud", jwt.MapClaims{"aud": []interface{}{"api", 1}}, []string{"api"}, false},
+	}
+
+	for _, data := range allAudiencesTestData {
+		if got := data.claims.VerifyAllAudiences(data.required); got != data.expected {
+			t.Errorf("[%v] Expected %v.  Got %v", data.name, data.expected, got)
+		}
+	}
+}
+
+func TestMapClaims_VerifyAudienceDecoded(t *testing.T) {
+	// Both encodings allowed by RFC 7519, as they come out of the JSON decoder
+	for _, raw := range []string{`{"aud":"foo"}`, `{"aud":["bar","foo"]}`} {
+		claims := jwt.MapClaims{}
+		if err := json.Unmarshal([]byte(raw), &claims); err != nil {
+			t.Fatal(err)
+		}
+		if !claims.VerifyAudience("foo", true) {
+			t.Errorf("[%v] Audience did not match", raw)
+		}
+	}
+}
+
+func TestMapClaims_VerifyIssuer(t *testing.T) {
+	claims := jwt.MapClaims{"iss": "test"}
+	if !claims.VerifyIssuer("test", true) {
+		t.Errorf("Issuer did not match")
+	}
+	if claims.VerifyIssuer("other", false) {
+		t.Errorf("Mismatched issuer passed verification")
+	}
+	if !(jwt.MapClaims{}).VerifyIssuer("test", false) {
+		t.Errorf("Missing issuer failed verification when not required")
+	}
+	if (jwt.MapClaims{}).VerifyIssuer("test", true) {
+		t.Errorf("Missing issuer passed verification when required")
+	}
+}
+
+func TestMapClaims_Getters(t *testing.T) {
+	claims := jwt.MapClaims{
+		"sub":    "user",
+		"float":  float64(1500),
+		"number": json.Number("1500"),
+		"frac":   json.Number("1500.5"),
+		"bad":    json.Number("abc"),
+	}
+
+	if v, ok := claims.GetString("sub"); !ok || v != "user" {
+		t.Errorf("GetString(sub) = %v, %v", v, ok)
+	}
+	if _, ok := claims.GetString("float"); ok {
+		t.Errorf("GetString should fail for a numeric claim")
+	}
+	if _, ok := claims.GetString("missing"); ok {
+		t.Errorf("GetString should fail for a missing claim")
+	}
+
+	var int64TestData = []struct {
+		key      string
+		expected int64
+		ok       bool
+	}{
+		{"float", 1500, true},
+		{"number", 1500, true},
+		{"frac", 1500, true},
+		{"bad", 0, false},
+		{"sub", 0, false},
+		{"missing", 0, false},
+	}
+	for _, data := range int64TestData {
+		if v, ok := claims.GetInt64(data.key); v != data.expected || ok != data.ok {
+			t.Errorf("[%v] GetInt64 = %v, %v; expected %v, %v", data.key, v, ok, data.expected, data.ok)
+		}
+	}
+
+	for _, key := range []string{"float", "number"} {
+		if v, ok := claims.GetTime(key); !ok || !v.Equal(time.Unix(1500, 0)) {
+			t.Errorf("[%v] GetTime = %v, %v", key, v, ok)
+		}
+	}
+	if v, ok := claims.GetTime("missing"); ok || !v.IsZero() {
+		t.Errorf("GetTime should fail for a missing claim.  Got %v, %v", v, ok)
+	}
+}
+
+func TestMapClaims_StringTimes(t *testing.T) {
+	var stringTimeTestData = []struct {
+		name   string
+		claims jwt.MapClaims
+		errors uint32
+	}{
+		{"numeric exp", jwt.MapClaims{"exp": float64(2000)}, 0},
+		{"numeric exp, expired", jwt.MapClaims{"exp": float64(500)}, jwt.ValidationErrorExpired},
+		{"json.Number exp, expired", jwt.MapClaims{"exp": json.Number("500")}, jwt.ValidationErrorExpired},
+		{"string exp", jwt.MapClaims{"exp": "2000"}, 0},
+		{"string exp, expired", jwt.MapClaims{"exp": "500"}, jwt.ValidationErrorExpired},
+		{"string nbf, not valid yet", jwt.MapClaims{"nbf": "2000"}, jwt.ValidationErrorNotValidYet},
+		{"garbage exp", jwt.MapClaims{"exp": "soon"}, jwt.ValidationErrorMalformed},
+		{"fractional string exp", jwt.MapClaims{"exp": "2000.5"}, jwt.ValidationErrorMalformed},
+		{"boolean nbf", jwt.MapClaims{"nbf": true}, jwt.ValidationErrorMalformed},
+		{"object iat", jwt.MapClaims{"iat": map[string]interface{}{}}, jwt.ValidationErrorMalformed},
+	}
+
+	for _, data := range stringTimeTestData {
+		var err error
+		at(time.Unix(1000, 0), func() {
+			err = data.claims.Valid()
+		})
+		if data.errors == 0 {
+			if err != nil {
+				t.Errorf("[%v] Error while validating claims: %v", data.name, err)
+			}
+			continue
+		}
+		if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != data.errors {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, data.errors)
+		}
+	}
+
+	// Verify methods fail closed on garbage, even when not required
+	if (jwt.MapClaims{"exp": "soon"}).VerifyExpiresAt(1000, false) {
+		t.Errorf("VerifyExpiresAt passed an unreadable exp")
+	}
+}
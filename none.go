@@ -1,5 +1,7 @@
 package jwt
 
+import "crypto"
+
 // Implements the none signing method.  This is required by the spec
 // but you probably should never use it.
 var SigningMethodNone *signingMethodNone
@@ -24,6 +26,11 @@ func (m *signingMethodNone) Alg() string {
 	return "none"
 }
 
+// Implements HashFuncer.  Nothing is hashed, so this is crypto.Hash(0).
+func (m *signingMethodNone) HashFunc() crypto.Hash {
+	return 0
+}
+
 // Only allow 'none' alg type if UnsafeAllowNoneSignatureType is specified as the key
 func (m *signingMethodNone) Verify(signingString, signature string, key interface{}) (err error) {
 	// Key must be UnsafeAllowNoneSignatureType to prevent accidentally
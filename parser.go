@@ -2,15 +2,104 @@ package jwt
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"time"
+	"unicode"
 )
 
+// The longest token string a Parser accepts if MaxTokenLength isn't set
+const DefaultMaxTokenLength = 64 * 1024
+
 type Parser struct {
 	ValidMethods         []string // If populated, only these methods will be considered valid
-	UseJSONNumber        bool     // Use JSON Number format in JSON decoder
+	UseJSONNumber        bool     // Use JSON Number format in JSON decoder.  Claims are then decoded with encoding/json, bypassing Unmarshal
 	SkipClaimsValidation bool     // Skip claims validation during token parsing
+
+	// Allowance for clock skew when validating the exp, nbf and iat claims.
+	// A token is expired once the current time is past exp + Leeway, and
+	// becomes valid at nbf - Leeway.  Defaults to zero (strict).
+	//
+	// Leeway applies to MapClaims, StandardClaims, RegisteredClaims and
+	// ExtendedClaims, and pointers to them.  Any other claims type, including
+	// one that embeds StandardClaims or RegisteredClaims, must implement
+	// LeewayClaims, or parsing fails with ErrLeewayUnsupported while a leeway
+	// is set.
+	Leeway time.Duration
+
+	// Override Leeway for exp and nbf alone, for issuers whose clocks are off
+	// in one direction.  A token is expired once the current time is past
+	// exp + ExpLeeway, and becomes valid at nbf - NbfLeeway.  Zero means
+	// Leeway is used.  They apply to the same claims types as Leeway, and are
+	// passed on to LeewayClaims.
+	ExpLeeway time.Duration
+	NbfLeeway time.Duration
+
+	// Tokens longer than this are rejected as malformed before any decoding
+	// happens, to limit the work an attacker can cause.  Zero means
+	// DefaultMaxTokenLength; a negative value disables the check.
+	MaxTokenLength int
+
+	// If populated, the typ header must be present and match one of these
+	// values, such as "JWT", or the token is rejected as malformed.  As per
+	// RFC 7515, the comparison ignores case and an "application/" prefix.
+	ValidTypes []string
+
+	// If populated, each of these claims must be present in the token's
+	// payload, whatever claims type it is decoded into.  A missing claim
+	// fails validation with ValidationErrorClaimsInvalid.
+	RequiredClaims []string
+
+	// Reject tokens without an exp claim, with ValidationErrorExpired, so none
+	// can be valid forever.  An exp of zero or less, which validation would
+	// otherwise treat as unset, is rejected the same way.  Like RequiredClaims
+	// this works with any claims type, and is skipped along with the rest of
+	// claims validation.
+	RequireExpiry bool
+
+	// If set, the aud claim must be present and contain ExpectedAudience, and
+	// the iss claim must be present and equal ExpectedIssuer.  Like
+	// RequiredClaims, these are checked against the token's payload whatever
+	// claims type it is decoded into.
+	ExpectedAudience string
+	ExpectedIssuer   string
+
+	// Header parameters the caller handles itself.  As per RFC 7515, tokens
+	// whose crit header lists anything else are rejected.  "b64" is always
+	// understood.
+	KnownCriticalParams []string
+
+	// Reject tokens whose header or claims repeat a key in any JSON object.
+	// encoding/json keeps the last value, and other implementations may not,
+	// which could let one token mean different things to different parsers.
+	// Keys differing only in case, such as "exp" and "EXP", count as repeats,
+	// since encoding/json matches struct fields ignoring case.
+	DisallowDuplicateKeys bool
+
+	// If set, called with the decoded header before the key is looked up or
+	// the signature is checked, to vet parameters such as jku or x5t.  A
+	// *ValidationError is returned as is; any other error is wrapped with
+	// ValidationErrorMalformed.
+	HeaderValidator func(header map[string]interface{}) error
+
+	// If set, called with the decoded claims after the built-in claim checks,
+	// to enforce rules of the caller's own, such as a required scope.  An
+	// error fails the parse; a *ValidationError is merged as is, and any other
+	// error is wrapped with ValidationErrorClaimsInvalid.  It is skipped along
+	// with the rest of claims validation.
+	ClaimsValidator func(claims Claims) error
+
+	// The encoding of the header, claims and signature segments.  Nil means
+	// the unpadded base64url JWS requires, as with DecodeSegment.  Set it, to
+	// base64.StdEncoding for instance, only to interoperate with systems that
+	// produce such non-standard tokens.  It applies only to parsing with this
+	// Parser: signing always uses base64url, and Token.SignatureValid expects
+	// it, so call it only on tokens parsed without an Encoding.
+	Encoding *base64.Encoding
 }
 
 // Parse, validate, and return a token.
@@ -26,6 +115,29 @@ func (p *Parser) ParseWithClaims(tokenString string, claims Claims, keyFunc Keyf
 		return token, err
 	}
 
+	// Verify token type is in the required set
+	if p.ValidTypes != nil {
+		typ, _ := token.Header["typ"].(string)
+		if !p.validType(typ) {
+			return token, NewValidationError(fmt.Sprintf("token type (typ) %q is invalid", typ), ValidationErrorMalformed)
+		}
+	}
+
+	// Verify every critical header parameter is understood
+	if err := p.verifyCritical(token.Header); err != nil {
+		return token, err
+	}
+
+	// Let the caller vet the header before any key is involved
+	if p.HeaderValidator != nil {
+		if err := p.HeaderValidator(token.Header); err != nil {
+			if ve, ok := err.(*ValidationError); ok {
+				return token, ve
+			}
+			return token, &ValidationError{Inner: err, Errors: ValidationErrorMalformed}
+		}
+	}
+
 	// Verify signing method is in the required set
 	if p.ValidMethods != nil {
 		var signingMethodValid = false
@@ -56,25 +168,41 @@ func (p *Parser) ParseWithClaims(tokenString string, claims Claims, keyFunc Keyf
 		return token, &ValidationError{Inner: err, Errors: ValidationErrorUnverifiable}
 	}
 
+	// A key of the wrong type, such as an RSA public key for an HMAC method,
+	// means the keyfunc is misconfigured.  Say so rather than failing the
+	// signature check.  A nil key is left to the method.
+	if c, ok := token.Method.(verificationKeyChecker); ok && key != nil {
+		if expected, ok := c.checkVerificationKey(key); !ok {
+			return token, &ValidationError{
+				Inner:  ErrInvalidKeyType,
+				Errors: ValidationErrorUnverifiable,
+				text:   fmt.Sprintf("%v requires a key of type %v, got %T", token.Method.Alg(), expected, key),
+			}
+		}
+	}
+
 	vErr := &ValidationError{}
 
 	// Validate Claims
 	if !p.SkipClaimsValidation {
-		if err := token.Claims.Valid(); err != nil {
-
-			// If the Claims Valid returned an error, check if it is a validation error,
-			// If it was another error type, create a ValidationError with a generic ClaimsInvalid flag set
-			if e, ok := err.(*ValidationError); !ok {
-				vErr = &ValidationError{Inner: err, Errors: ValidationErrorClaimsInvalid}
-			} else {
-				vErr = e
-			}
+		var claimBytes []byte
+		if p.verifiesPayload() {
+			// Errors can be ignored, ParseUnverified already decoded this segment
+			claimBytes, _ = p.decodePayload(token.Header, parts[1])
 		}
+		vErr = p.checkClaims(token.Claims, claimBytes)
 	}
 
 	// Perform validation
 	token.Signature = parts[2]
-	if err = token.Method.Verify(strings.Join(parts[0:2], "."), token.Signature, key); err != nil {
+	if token.Signature == "" && token.Method != SigningMethodNone {
+		// Say so plainly rather than leaving it to the method's decoding
+		vErr.Inner = errors.New("token signature is empty")
+		vErr.Errors |= ValidationErrorSignatureInvalid
+	} else if sig, err := p.verificationSignature(token.Signature); err != nil {
+		vErr.Inner = err
+		vErr.Errors |= ValidationErrorSignatureInvalid
+	} else if err = token.Method.Verify(signingInput(token.Raw, parts), sig, key); err != nil {
 		vErr.Inner = err
 		vErr.Errors |= ValidationErrorSignatureInvalid
 	}
@@ -87,6 +215,94 @@ func (p *Parser) ParseWithClaims(tokenString string, claims Claims, keyFunc Keyf
 	return token, vErr
 }
 
+// Runs the claims validation Parse performs, with the parser's settings, on a
+// token that was parsed earlier or built in code, without touching the
+// signature.  Unlike Token.ValidateClaims, this applies the leeway,
+// RequiredClaims, RequireExpiry, ExpectedAudience, ExpectedIssuer and
+// ClaimsValidator.  The payload checks run against the claims as they are now,
+// encoded with Marshal.  SkipClaimsValidation is ignored, since validating is
+// what was asked for.
+func (p *Parser) ValidateClaims(token *Token) error {
+	if token.Claims == nil {
+		return NewValidationError("token has no claims", ValidationErrorClaimsInvalid)
+	}
+	var claimBytes []byte
+	if p.verifiesPayload() {
+		var err error
+		if claimBytes, err = Marshal(token.Claims); err != nil {
+			return &ValidationError{Inner: err, Errors: ValidationErrorClaimsInvalid}
+		}
+	}
+	if vErr := p.checkClaims(token.Claims, claimBytes); !vErr.valid() {
+		return vErr
+	}
+	return nil
+}
+
+// Runs every claims check the parser is configured for.  claimBytes is the
+// encoded claims, needed only if verifiesPayload.  The result is valid if
+// they all pass.
+func (p *Parser) checkClaims(claims Claims, claimBytes []byte) *ValidationError {
+	vErr := &ValidationError{}
+	if err := p.validateClaims(claims); err != nil {
+		vErr = claimsValidationError(err)
+	}
+
+	if e := p.verifyPayload(claimBytes); e != nil {
+		if vErr.valid() {
+			vErr = e
+		} else {
+			vErr.Errors |= e.Errors
+		}
+	}
+
+	if p.ClaimsValidator != nil {
+		if err := p.ClaimsValidator(claims); err != nil {
+			if e := claimsValidationError(err); vErr.valid() {
+				vErr = e
+			} else {
+				vErr.Errors |= e.Errors
+			}
+		}
+	}
+	return vErr
+}
+
+// Validates claims, passing the parser's settings on to the claims types
+// that understand them
+func (p *Parser) validateClaims(claims Claims) error {
+	if v := p.validator(); v != (validator{}) {
+		if c, ok := builtinValidatorClaims(claims); ok {
+			return c.valid(v)
+		}
+		if c, ok := claims.(LeewayClaims); ok {
+			return c.ValidWithLeeway(v.expLeeway, v.nbfLeeway, v.iatLeeway)
+		}
+		return ErrLeewayUnsupported
+	}
+	return claims.Valid()
+}
+
+func (p *Parser) validator() validator {
+	v := validator{expLeeway: p.Leeway, nbfLeeway: p.Leeway, iatLeeway: p.Leeway}
+	if p.ExpLeeway != 0 {
+		v.expLeeway = p.ExpLeeway
+	}
+	if p.NbfLeeway != 0 {
+		v.nbfLeeway = p.NbfLeeway
+	}
+	return v
+}
+
+// If the Claims Valid returned an error, check if it is a validation error,
+// If it was another error type, create a ValidationError with a generic ClaimsInvalid flag set
+func claimsValidationError(err error) *ValidationError {
+	if e, ok := err.(*ValidationError); ok {
+		return e
+	}
+	return &ValidationError{Inner: err, Errors: ValidationErrorClaimsInvalid}
+}
+
 // WARNING: Don't use this method unless you know what you're doing
 //
 // This method parses the token but doesn't validate the signature. It's only
@@ -94,55 +310,381 @@ func (p *Parser) ParseWithClaims(tokenString string, claims Claims, keyFunc Keyf
 // been checked previously in the stack) and you want to extract values from
 // it.
 func (p *Parser) ParseUnverified(tokenString string, claims Claims) (token *Token, parts []string, err error) {
+	if max := p.maxTokenLength(); max >= 0 && len(tokenString) > max {
+		return nil, nil, NewValidationError("token is too long", ValidationErrorMalformed)
+	}
+
+	// Tokens copied from headers or files often pick up stray whitespace
+	tokenString = strings.TrimSpace(tokenString)
+
+	// Signed tokens have three segments.  Unsigned tokens may leave the
+	// signature segment off entirely, which is checked once alg is known.
 	parts = strings.Split(tokenString, ".")
-	if len(parts) != 3 {
+	if len(parts) == 5 {
+		// The JWE compact serialization, as per RFC 7516
+		return nil, parts, NewValidationError("token is a JWE (encrypted), not a JWS; JWE is not supported", ValidationErrorMalformed)
+	}
+	if len(parts) != 2 && len(parts) != 3 {
 		return nil, parts, NewValidationError("token contains an invalid number of segments", ValidationErrorMalformed)
 	}
 
-	token = &Token{Raw: tokenString}
+	token = &Token{Raw: tokenString, Parts: parts}
+
+	if strings.HasPrefix(strings.ToLower(tokenString), "bearer ") {
+		return token, parts, NewValidationError("tokenstring should not contain 'bearer '", ValidationErrorMalformed)
+	}
+
+	// The payload may legitimately hold whitespace if it's unencoded, so it
+	// is checked once the header has been read
+	for i, part := range parts {
+		if i != 1 && containsSpace(part) {
+			return token, parts, NewValidationError("token contains whitespace", ValidationErrorMalformed)
+		}
+	}
 
 	// parse Header
 	var headerBytes []byte
-	if headerBytes, err = DecodeSegment(parts[0]); err != nil {
-		if strings.HasPrefix(strings.ToLower(tokenString), "bearer ") {
-			return token, parts, NewValidationError("tokenstring should not contain 'bearer '", ValidationErrorMalformed)
+	if headerBytes, err = p.decodeSegment(parts[0]); err != nil {
+		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed, text: "could not base64-decode header"}
+	}
+	if !isJSONObject(headerBytes) {
+		return token, parts, NewValidationError("header segment is not a JSON object", ValidationErrorMalformed)
+	}
+	if err = Unmarshal(headerBytes, &token.Header); err != nil {
+		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed, text: "could not JSON-decode header"}
+	}
+	if p.DisallowDuplicateKeys {
+		if err := checkDuplicateKeys(headerBytes, "header"); err != nil {
+			return token, parts, err
 		}
-		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed}
 	}
-	if err = json.Unmarshal(headerBytes, &token.Header); err != nil {
-		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed}
+	if _, ok := token.Header["zip"]; ok {
+		return token, parts, &ValidationError{Inner: ErrZipNotSupported, Errors: ValidationErrorMalformed}
+	}
+	if unencodedPayload(token.Header) {
+		if !isCritical(token.Header, "b64") {
+			return token, parts, &ValidationError{Inner: ErrB64NotCritical, Errors: ValidationErrorMalformed}
+		}
+	} else if containsSpace(parts[1]) {
+		return token, parts, NewValidationError("token contains whitespace", ValidationErrorMalformed)
 	}
 
 	// parse Claims
 	var claimBytes []byte
 	token.Claims = claims
 
-	if claimBytes, err = DecodeSegment(parts[1]); err != nil {
-		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed}
+	if claimBytes, err = p.decodePayload(token.Header, parts[1]); err != nil {
+		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed, text: "could not base64-decode claims"}
 	}
-	dec := json.NewDecoder(bytes.NewBuffer(claimBytes))
-	if p.UseJSONNumber {
-		dec.UseNumber()
+	if !isJSONObject(claimBytes) {
+		return token, parts, NewValidationError("claims segment is not a JSON object", ValidationErrorMalformed)
+	}
+	if p.DisallowDuplicateKeys {
+		if err := checkDuplicateKeys(claimBytes, "claims"); err != nil {
+			return token, parts, err
+		}
 	}
 	// JSON Decode.  Special case for map type to avoid weird pointer behavior
+	var dst interface{} = &claims
 	if c, ok := token.Claims.(MapClaims); ok {
-		err = dec.Decode(&c)
+		dst = &c
+	}
+	if p.UseJSONNumber {
+		dec := json.NewDecoder(bytes.NewBuffer(claimBytes))
+		dec.UseNumber()
+		if err = dec.Decode(dst); err == nil {
+			// Reject trailing data, as Unmarshal does
+			if dec.Decode(new(json.RawMessage)) != io.EOF {
+				err = errors.New("invalid data after top-level value")
+			}
+		}
 	} else {
-		err = dec.Decode(&claims)
+		err = Unmarshal(claimBytes, dst)
 	}
 	// Handle decode error
 	if err != nil {
-		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed}
+		return token, parts, &ValidationError{Inner: err, Errors: ValidationErrorMalformed, text: "could not JSON-decode claims"}
 	}
 
 	// Lookup signature method
-	if method, ok := token.Header["alg"].(string); ok {
-		if token.Method = GetSigningMethod(method); token.Method == nil {
-			return token, parts, NewValidationError("signing method (alg) is unavailable.", ValidationErrorUnverifiable)
+	alg, present := token.Header["alg"]
+	method, ok := alg.(string)
+	switch {
+	case !present:
+		return token, parts, &ValidationError{Inner: ErrAlgMissing, Errors: ValidationErrorMalformed}
+	case !ok:
+		return token, parts, &ValidationError{Inner: ErrAlgNotString, Errors: ValidationErrorMalformed}
+	case method == "":
+		return token, parts, &ValidationError{Inner: ErrAlgEmpty, Errors: ValidationErrorMalformed}
+	}
+	if token.Method = GetSigningMethod(method); token.Method == nil {
+		return token, parts, &ValidationError{Inner: ErrSigningMethodUnavailable, Errors: ValidationErrorUnverifiable}
+	}
+
+	if len(parts) == 2 {
+		if token.Method != SigningMethodNone {
+			return token, parts, NewValidationError("token contains an invalid number of segments", ValidationErrorMalformed)
 		}
-	} else {
-		return token, parts, NewValidationError("signing method (alg) is unspecified.", ValidationErrorUnverifiable)
+		parts = append(parts, "")
+		token.Parts = parts
 	}
 
 	return token, parts, nil
 }
+
+// Reports whether verifyPayload has anything to check
+func (p *Parser) verifiesPayload() bool {
+	return len(p.RequiredClaims) != 0 || p.RequireExpiry || p.ExpectedAudience != "" || p.ExpectedIssuer != ""
+}
+
+// Checks RequiredClaims, ExpectedAudience and ExpectedIssuer against the
+// encoded claims
+func (p *Parser) verifyPayload(claimBytes []byte) *ValidationError {
+	if !p.verifiesPayload() {
+		return nil
+	}
+
+	var present map[string]json.RawMessage
+	Unmarshal(claimBytes, &present)
+
+	vErr := new(ValidationError)
+
+	var missing []string
+	for _, name := range p.RequiredClaims {
+		if _, ok := present[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		vErr.text = fmt.Sprintf("token is missing required claims: %v", strings.Join(missing, ", "))
+		vErr.Errors |= ValidationErrorClaimsInvalid
+	}
+
+	if p.RequireExpiry {
+		if raw, ok := present["exp"]; !ok || string(raw) == "null" {
+			if vErr.text == "" {
+				vErr.text = "token has no expiry (exp) claim"
+			}
+			vErr.Errors |= ValidationErrorExpired
+		} else if exp, err := rawNumericDate(raw); err != nil || exp <= 0 {
+			// Validation treats an exp of zero as unset, so it would never expire
+			if vErr.text == "" {
+				vErr.text = "token has an invalid expiry (exp) claim"
+			}
+			vErr.Errors |= ValidationErrorExpired
+		}
+	}
+
+	if p.ExpectedAudience != "" {
+		var aud ClaimStrings
+		if raw, ok := present["aud"]; !ok || Unmarshal(raw, &aud) != nil || !verifyAud(aud, p.ExpectedAudience, true) {
+			if vErr.text == "" {
+				vErr.text = "token has invalid audience"
+			}
+			vErr.Errors |= ValidationErrorAudience
+		}
+	}
+
+	if p.ExpectedIssuer != "" {
+		var iss string
+		if raw, ok := present["iss"]; !ok || Unmarshal(raw, &iss) != nil || !verifyIss(iss, p.ExpectedIssuer, true) {
+			if vErr.text == "" {
+				vErr.text = "token has invalid issuer"
+			}
+			vErr.Errors |= ValidationErrorIssuer
+		}
+	}
+
+	if vErr.valid() {
+		return nil
+	}
+	return vErr
+}
+
+// Decodes a raw claim as a NumericDate, accepting what MapClaims does
+func rawNumericDate(raw json.RawMessage) (int64, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return 0, err
+	}
+	v, _, err := numericDate(value)
+	return v, err
+}
+
+func (p *Parser) verifyCritical(header map[string]interface{}) *ValidationError {
+	raw, ok := header["crit"]
+	if !ok {
+		return nil
+	}
+
+	crit, _ := raw.([]interface{})
+	if len(crit) == 0 {
+		return NewValidationError("crit header must be a non-empty array of strings", ValidationErrorMalformed)
+	}
+	for _, c := range crit {
+		name, ok := c.(string)
+		if !ok {
+			return NewValidationError("crit header must be a non-empty array of strings", ValidationErrorMalformed)
+		}
+		if _, ok := header[name]; !ok {
+			return NewValidationError(fmt.Sprintf("critical header parameter %q is missing", name), ValidationErrorMalformed)
+		}
+		if !p.knownCritical(name) {
+			return NewValidationError(fmt.Sprintf("critical header parameter %q is not supported", name), ValidationErrorUnverifiable)
+		}
+	}
+	return nil
+}
+
+func (p *Parser) knownCritical(name string) bool {
+	if name == "b64" {
+		return true
+	}
+	for _, known := range p.KnownCriticalParams {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a malformed error if any object in data repeats a key.  Keys are
+// compared ignoring case, since encoding/json matches struct fields that way:
+// "exp" and "EXP" would both set StandardClaims.ExpiresAt.  Invalid JSON is
+// left for the regular decoding to report.
+func checkDuplicateKeys(data []byte, segment string) *ValidationError {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if key, _ := duplicateKey(dec); key != "" {
+		return NewValidationError(fmt.Sprintf("duplicate key %q in %v", key, segment), ValidationErrorMalformed)
+	}
+	return nil
+}
+
+// Walks the next JSON value from dec, returning the first repeated key found
+func duplicateKey(dec *json.Decoder) (string, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		seen := map[string]bool{}
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return "", err
+			}
+			key, _ := t.(string)
+			folded := foldKey(key)
+			if seen[folded] {
+				return key, nil
+			}
+			seen[folded] = true
+			if dup, err := duplicateKey(dec); dup != "" || err != nil {
+				return dup, err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for dec.More() {
+			if dup, err := duplicateKey(dec); dup != "" || err != nil {
+				return dup, err
+			}
+		}
+		_, err = dec.Token()
+	}
+	return "", err
+}
+
+// Returns key with each rune replaced by the smallest rune it case folds to,
+// so keys that strings.EqualFold considers equal give the same result
+func foldKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, key)
+}
+
+// Returns the claims JSON from the payload segment, which RFC 7797 allows
+// to be sent unencoded
+func (p *Parser) decodePayload(header map[string]interface{}, seg string) ([]byte, error) {
+	if unencodedPayload(header) {
+		return []byte(seg), nil
+	}
+	return p.decodeSegment(seg)
+}
+
+// Decodes seg with the parser's Encoding
+func (p *Parser) decodeSegment(seg string) ([]byte, error) {
+	if p.Encoding == nil {
+		return DecodeSegment(seg)
+	}
+	return p.Encoding.DecodeString(seg)
+}
+
+// The methods decode signatures with DecodeSegment, so one in another
+// Encoding is re-encoded for them
+func (p *Parser) verificationSignature(sig string) (string, error) {
+	if p.Encoding == nil || sig == "" {
+		return sig, nil
+	}
+	b, err := p.Encoding.DecodeString(sig)
+	if err != nil {
+		return "", err
+	}
+	return EncodeSegment(b), nil
+}
+
+// Returns the header and payload segments as signed.  parts was split from
+// raw, so slicing it saves joining them again on every Parse.
+func signingInput(raw string, parts []string) string {
+	return raw[:len(parts[0])+1+len(parts[1])]
+}
+
+// Reports whether the JSON value in b is an object.  Anything else, such as
+// an array or null, would either fail to decode into the header and claims
+// with an unhelpful error or silently decode to nothing.
+func isJSONObject(b []byte) bool {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	return len(b) > 0 && b[0] == '{'
+}
+
+func containsSpace(s string) bool {
+	return strings.IndexFunc(s, unicode.IsSpace) >= 0
+}
+
+func (p *Parser) maxTokenLength() int {
+	if p.MaxTokenLength == 0 {
+		return DefaultMaxTokenLength
+	}
+	return p.MaxTokenLength
+}
+
+func (p *Parser) validType(typ string) bool {
+	if typ == "" {
+		return false
+	}
+	typ = trimMediaTypePrefix(typ)
+	for _, t := range p.ValidTypes {
+		if strings.EqualFold(typ, trimMediaTypePrefix(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+func trimMediaTypePrefix(typ string) string {
+	if len(typ) > len("application/") && strings.EqualFold(typ[:len("application/")], "application/") {
+		return typ[len("application/"):]
+	}
+	return typ
+}
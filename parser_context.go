@@ -0,0 +1,40 @@
+//go:build go1.7
+// +build go1.7
+
+package jwt
+
+import "context"
+
+// Like Keyfunc, but also receives the context passed to ParseWithContext so
+// that key lookups such as JWKS fetches or database calls can honor its
+// cancellation and deadline.
+type ContextKeyfunc func(context.Context, *Token) (interface{}, error)
+
+// Like Parse, but passes ctx on to keyFunc.  If ctx is already done when the
+// key is needed, parsing stops with ctx.Err() as the inner error.
+func (p *Parser) ParseWithContext(ctx context.Context, tokenString string, keyFunc ContextKeyfunc) (*Token, error) {
+	return p.ParseWithClaimsContext(ctx, tokenString, MapClaims{}, keyFunc)
+}
+
+// Like ParseWithClaims, but passes ctx on to keyFunc.  See ParseWithContext
+func (p *Parser) ParseWithClaimsContext(ctx context.Context, tokenString string, claims Claims, keyFunc ContextKeyfunc) (*Token, error) {
+	if keyFunc == nil {
+		return p.ParseWithClaims(tokenString, claims, nil)
+	}
+	return p.ParseWithClaims(tokenString, claims, func(token *Token) (interface{}, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return keyFunc(ctx, token)
+	})
+}
+
+// Parse with the default parser, passing ctx on to keyFunc.  See Parser.ParseWithContext
+func ParseWithContext(ctx context.Context, tokenString string, keyFunc ContextKeyfunc, options ...ParserOption) (*Token, error) {
+	return NewParser(options...).ParseWithContext(ctx, tokenString, keyFunc)
+}
+
+// Parse with the default parser into claims, passing ctx on to keyFunc.  See Parser.ParseWithContext
+func ParseWithClaimsContext(ctx context.Context, tokenString string, claims Claims, keyFunc ContextKeyfunc, options ...ParserOption) (*Token, error) {
+	return NewParser(options...).ParseWithClaimsContext(ctx, tokenString, claims, keyFunc)
+}
@@ -0,0 +1,66 @@
+//go:build go1.7
+// +build go1.7
+
+package jwt_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestParseWithContext(t *testing.T) {
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"}).SignedString(hmacTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "tenant-1")
+	token, err := jwt.ParseWithContext(ctx, tokenString, func(ctx context.Context, token *jwt.Token) (interface{}, error) {
+		if ctx.Value(ctxKey{}) != "tenant-1" {
+			return nil, errors.New("context not passed to keyfunc")
+		}
+		return hmacTestKey, nil
+	})
+	if err != nil || !token.Valid {
+		t.Errorf("Error while verifying token: %v", err)
+	}
+}
+
+func TestParseWithContext_Cancelled(t *testing.T) {
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"}).SignedString(hmacTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A keyfunc that blocks until its context is done, like a slow fetch
+	keyFunc := func(ctx context.Context, token *jwt.Token) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	var cancelledTestData = []struct {
+		name   string
+		cancel func(context.CancelFunc)
+	}{
+		{"cancelled before parse", func(cancel context.CancelFunc) { cancel() }},
+		{"cancelled during lookup", func(cancel context.CancelFunc) { go cancel() }},
+	}
+
+	for _, data := range cancelledTestData {
+		ctx, cancel := context.WithCancel(context.Background())
+		data.cancel(cancel)
+		token, err := new(jwt.Parser).ParseWithClaimsContext(ctx, tokenString, jwt.MapClaims{}, keyFunc)
+		cancel()
+
+		if token == nil || token.Valid {
+			t.Errorf("[%v] Expected an invalid token", data.name)
+		}
+		if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorUnverifiable || ve.Inner != context.Canceled {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, context.Canceled)
+		}
+	}
+}
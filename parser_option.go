@@ -0,0 +1,138 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"time"
+)
+
+// ParserOption configures a Parser.  Pass them to NewParser, Parse or
+// ParseWithClaims.
+type ParserOption func(*Parser)
+
+// Creates a Parser with the given options applied
+func NewParser(options ...ParserOption) *Parser {
+	p := new(Parser)
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Only accept tokens signed with one of these algorithms.  See Parser.ValidMethods
+func WithValidMethods(methods []string) ParserOption {
+	return func(p *Parser) {
+		p.ValidMethods = methods
+	}
+}
+
+// Decode numbers in the claims as json.Number.  See Parser.UseJSONNumber
+func WithJSONNumber() ParserOption {
+	return func(p *Parser) {
+		p.UseJSONNumber = true
+	}
+}
+
+// Only verify the signature, skipping claims validation.  See Parser.SkipClaimsValidation
+func WithoutClaimsValidation() ParserOption {
+	return func(p *Parser) {
+		p.SkipClaimsValidation = true
+	}
+}
+
+// Allow for clock skew when validating time based claims.  See Parser.Leeway
+func WithLeeway(leeway time.Duration) ParserOption {
+	return func(p *Parser) {
+		p.Leeway = leeway
+	}
+}
+
+// Allow for clock skew when validating exp alone.  See Parser.ExpLeeway
+func WithExpLeeway(leeway time.Duration) ParserOption {
+	return func(p *Parser) {
+		p.ExpLeeway = leeway
+	}
+}
+
+// Allow for clock skew when validating nbf alone.  See Parser.NbfLeeway
+func WithNbfLeeway(leeway time.Duration) ParserOption {
+	return func(p *Parser) {
+		p.NbfLeeway = leeway
+	}
+}
+
+// Limit the length of accepted tokens.  See Parser.MaxTokenLength
+func WithMaxTokenLength(length int) ParserOption {
+	return func(p *Parser) {
+		p.MaxTokenLength = length
+	}
+}
+
+// Require the typ header to be one of these values.  See Parser.ValidTypes
+func WithValidTypes(types []string) ParserOption {
+	return func(p *Parser) {
+		p.ValidTypes = types
+	}
+}
+
+// Accept tokens marking these header parameters as critical.  See Parser.KnownCriticalParams
+func WithKnownCriticalParams(params []string) ParserOption {
+	return func(p *Parser) {
+		p.KnownCriticalParams = params
+	}
+}
+
+// Reject tokens that repeat a JSON key.  See Parser.DisallowDuplicateKeys
+func WithDisallowDuplicateKeys() ParserOption {
+	return func(p *Parser) {
+		p.DisallowDuplicateKeys = true
+	}
+}
+
+// Require these claims to be present.  See Parser.RequiredClaims
+func WithRequiredClaims(claims []string) ParserOption {
+	return func(p *Parser) {
+		p.RequiredClaims = claims
+	}
+}
+
+// Vet the token's header before verification.  See Parser.HeaderValidator
+func WithHeaderValidator(f func(header map[string]interface{}) error) ParserOption {
+	return func(p *Parser) {
+		p.HeaderValidator = f
+	}
+}
+
+// Enforce the caller's own claim rules.  See Parser.ClaimsValidator
+func WithClaimsValidator(f func(claims Claims) error) ParserOption {
+	return func(p *Parser) {
+		p.ClaimsValidator = f
+	}
+}
+
+// Decode segments with a non-standard encoding.  See Parser.Encoding
+func WithEncoding(encoding *base64.Encoding) ParserOption {
+	return func(p *Parser) {
+		p.Encoding = encoding
+	}
+}
+
+// Reject tokens without an exp claim.  See Parser.RequireExpiry
+func WithRequireExpiry() ParserOption {
+	return func(p *Parser) {
+		p.RequireExpiry = true
+	}
+}
+
+// Require the aud claim to contain this audience.  See Parser.ExpectedAudience
+func WithAudience(aud string) ParserOption {
+	return func(p *Parser) {
+		p.ExpectedAudience = aud
+	}
+}
+
+// Require the iss claim to equal this issuer.  See Parser.ExpectedIssuer
+func WithIssuer(iss string) ParserOption {
+	return func(p *Parser) {
+		p.ExpectedIssuer = iss
+	}
+}
@@ -0,0 +1,94 @@
+package jwt_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestNewParser(t *testing.T) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"HS256"}),
+		jwt.WithJSONNumber(),
+		jwt.WithoutClaimsValidation(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithExpLeeway(time.Second),
+		jwt.WithNbfLeeway(time.Hour),
+		jwt.WithMaxTokenLength(128),
+		jwt.WithValidTypes([]string{"JWT"}),
+		jwt.WithKnownCriticalParams([]string{"exp"}),
+		jwt.WithDisallowDuplicateKeys(),
+		jwt.WithRequiredClaims([]string{"sub"}),
+		jwt.WithRequireExpiry(),
+		jwt.WithAudience("myapi"),
+		jwt.WithIssuer("https://idp"),
+		jwt.WithEncoding(base64.StdEncoding),
+	)
+
+	expected := &jwt.Parser{
+		ValidMethods:          []string{"HS256"},
+		UseJSONNumber:         true,
+		SkipClaimsValidation:  true,
+		Leeway:                time.Minute,
+		ExpLeeway:             time.Second,
+		NbfLeeway:             time.Hour,
+		MaxTokenLength:        128,
+		ValidTypes:            []string{"JWT"},
+		KnownCriticalParams:   []string{"exp"},
+		DisallowDuplicateKeys: true,
+		RequiredClaims:        []string{"sub"},
+		RequireExpiry:         true,
+		ExpectedAudience:      "myapi",
+		ExpectedIssuer:        "https://idp",
+		Encoding:              base64.StdEncoding,
+	}
+	if !reflect.DeepEqual(parser, expected) {
+		t.Errorf("Options not applied.\nwas:\n%+v\nexpecting:\n%+v", parser, expected)
+	}
+}
+
+func TestParse_Options(t *testing.T) {
+	keyFunc := func(*jwt.Token) (interface{}, error) { return hmacTestKey, nil }
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": float64(1000)}).SignedString(hmacTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Expired without options, accepted within the leeway
+	at(time.Unix(1005, 0), func() {
+		_, err = jwt.Parse(tokenString, keyFunc)
+	})
+	if err == nil {
+		t.Errorf("Expired token passed validation")
+	}
+	at(time.Unix(1005, 0), func() {
+		_, err = jwt.Parse(tokenString, keyFunc, jwt.WithLeeway(10*time.Second))
+	})
+	if err != nil {
+		t.Errorf("Error while verifying token within leeway: %v", err)
+	}
+
+	// Signing method not in the allowed set
+	at(time.Unix(500, 0), func() {
+		_, err = jwt.Parse(tokenString, keyFunc, jwt.WithValidMethods([]string{"RS256"}))
+	})
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorSignatureInvalid {
+		t.Errorf("Expected ValidationErrorSignatureInvalid.  Got %v", err)
+	}
+
+	// Numbers decoded as json.Number
+	var token *jwt.Token
+	at(time.Unix(500, 0), func() {
+		token, err = jwt.ParseWithClaims(tokenString, jwt.MapClaims{}, keyFunc, jwt.WithJSONNumber())
+	})
+	if err != nil {
+		t.Fatalf("Error while verifying token: %v", err)
+	}
+	if _, ok := token.Claims.(jwt.MapClaims)["exp"].(json.Number); !ok {
+		t.Errorf("Expected exp to be a json.Number.  Got %T", token.Claims.(jwt.MapClaims)["exp"])
+	}
+}
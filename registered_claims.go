@@ -0,0 +1,93 @@
+package jwt
+
+import "time"
+
+// Structured version of the registered claims, as referenced at
+// https://tools.ietf.org/html/rfc7519#section-4.1
+// Unlike StandardClaims, the Audience may hold several values and is decoded
+// from either a string or an array of strings.
+//
+// To add private claims, embed RegisteredClaims in your own struct and pass a
+// pointer to it to ParseWithClaims, so the embedded fields are filled in and
+// Valid is promoted.  Give your fields their own json names, since one named
+// like a registered claim would shadow it.  If the extra claims aren't known
+// in advance, use ExtendedClaims instead.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  ClaimStrings `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// Validates time based claims "exp, iat, nbf".
+// There is no accounting for clock skew.  Use Parser.Leeway to allow for it.
+// As well, if any of the above claims are not in the token, it will still
+// be considered a valid claim.
+func (c RegisteredClaims) Valid() error {
+	return c.valid(validator{})
+}
+
+func (c RegisteredClaims) valid(v validator) error {
+	return StandardClaims{
+		ExpiresAt: c.ExpiresAt.unix(),
+		IssuedAt:  c.IssuedAt.unix(),
+		NotBefore: c.NotBefore.unix(),
+	}.valid(v)
+}
+
+func (c RegisteredClaims) expiresAt() int64 {
+	return c.ExpiresAt.unix()
+}
+
+func (c *RegisteredClaims) setTimeClaim(name string, unix int64) {
+	date := NewNumericDate(time.Unix(unix, 0))
+	switch name {
+	case "exp":
+		c.ExpiresAt = date
+	case "iat":
+		c.IssuedAt = date
+	case "nbf":
+		c.NotBefore = date
+	}
+}
+
+// Compares the aud claim against cmp.  Passes if any of the audiences match.
+// If required is false, this method will return true if the value matches or is unset
+func (c *RegisteredClaims) VerifyAudience(cmp string, req bool) bool {
+	return verifyAud(c.Audience, cmp, req)
+}
+
+// Compares the exp claim against cmp.
+// If required is false, this method will return true if the value matches or is unset
+func (c *RegisteredClaims) VerifyExpiresAt(cmp int64, req bool) bool {
+	return verifyExp(c.ExpiresAt.unix(), cmp, req)
+}
+
+// Compares the iat claim against cmp.
+// If required is false, this method will return true if the value matches or is unset
+func (c *RegisteredClaims) VerifyIssuedAt(cmp int64, req bool) bool {
+	return verifyIat(c.IssuedAt.unix(), cmp, req)
+}
+
+// Compares the iss claim against cmp.
+// If required is false, this method will return true if the value matches or is unset
+func (c *RegisteredClaims) VerifyIssuer(cmp string, req bool) bool {
+	return verifyIss(c.Issuer, cmp, req)
+}
+
+// Compares the nbf claim against cmp.
+// If required is false, this method will return true if the value matches or is unset
+func (c *RegisteredClaims) VerifyNotBefore(cmp int64, req bool) bool {
+	return verifyNbf(c.NotBefore.unix(), cmp, req)
+}
+
+// Copies c along with its Audience, for Token.Clone
+func (c RegisteredClaims) deepCopy() RegisteredClaims {
+	if c.Audience != nil {
+		c.Audience = append(ClaimStrings{}, c.Audience...)
+	}
+	return c
+}
@@ -4,4 +4,7 @@
 // The main function is ParseFromRequest and it's WithClaims variant.
 // See examples for how to use the various Extractor implementations
 // or roll your own.
+//
+// Middleware wraps an http.Handler so that only requests carrying a valid
+// token reach it.  The token is available to the handler through FromContext.
 package request
@@ -3,11 +3,13 @@ package request
 import (
 	"errors"
 	"net/http"
+	"strings"
 )
 
 // Errors
 var (
-	ErrNoTokenInRequest = errors.New("no token present in request")
+	ErrNoTokenInRequest      = errors.New("no token present in request")
+	ErrUnsupportedAuthScheme = errors.New("authorization scheme is not supported")
 )
 
 // Interface for extracting a token from an HTTP request.
@@ -31,6 +33,25 @@ func (e HeaderExtractor) ExtractToken(req *http.Request) (string, error) {
 	return "", ErrNoTokenInRequest
 }
 
+// Returns an Extractor for a token in a non-standard header, such as
+// X-Auth-Token set by a gateway.  If prefix is not empty, the header value
+// must start with it, ignoring case.  The prefix and surrounding whitespace
+// are stripped, and a value without the prefix counts as no token.
+func MappedHeaderExtractor(header, prefix string) Extractor {
+	return &PostExtractionFilter{
+		HeaderExtractor{header},
+		func(tok string) (string, error) {
+			if prefix == "" {
+				return tok, nil
+			}
+			if len(tok) < len(prefix) || !strings.EqualFold(tok[:len(prefix)], prefix) {
+				return "", ErrNoTokenInRequest
+			}
+			return strings.TrimSpace(tok[len(prefix):]), nil
+		},
+	}
+}
+
 // Extract token from request arguments.  This includes a POSTed form or
 // GET URL arguments.  Argument names are tried in order until there's a match.
 // This extractor calls `ParseMultipartForm` on the request
@@ -50,6 +71,41 @@ func (e ArgumentExtractor) ExtractToken(req *http.Request) (string, error) {
 	return "", ErrNoTokenInRequest
 }
 
+// Extract token from a url-encoded POST body, such as a login callback's
+// access_token field.  Unlike ArgumentExtractor, URL query arguments are
+// ignored.  Field names are tried in order until there's a match.
+// This extractor calls `ParseForm` on the request, which reads the body.
+// The body can't be read again afterwards, but the parsed values stay
+// available to later handlers through `req.PostForm` and `req.Form`.
+type PostExtractor []string
+
+func (e PostExtractor) ExtractToken(req *http.Request) (string, error) {
+	if err := req.ParseForm(); err != nil {
+		return "", err
+	}
+
+	for _, field := range e {
+		if tok := req.PostForm.Get(field); tok != "" {
+			return tok, nil
+		}
+	}
+	return "", ErrNoTokenInRequest
+}
+
+// Extractor for finding a token in a cookie.  Looks at each specified
+// cookie name in order until there's a match
+type CookieExtractor []string
+
+func (e CookieExtractor) ExtractToken(req *http.Request) (string, error) {
+	// loop over cookie names and return the first one that contains data
+	for _, name := range e {
+		if cookie, err := req.Cookie(name); err == nil && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+	}
+	return "", ErrNoTokenInRequest
+}
+
 // Tries Extractors in order until one returns a token string or an error occurs
 type MultiExtractor []Extractor
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +46,77 @@ var extractorTestData = []struct {
 		token:   extractorTestTokenA,
 		err:     nil,
 	},
+	{
+		name:      "mapped header",
+		extractor: MappedHeaderExtractor("X-Auth-Token", ""),
+		headers:   map[string]string{"X-Auth-Token": extractorTestTokenA},
+		query:     nil,
+		token:     extractorTestTokenA,
+		err:       nil,
+	},
+	{
+		name:      "mapped header with prefix",
+		extractor: MappedHeaderExtractor("X-Auth-Token", "Token "),
+		headers:   map[string]string{"X-Auth-Token": "token " + extractorTestTokenA},
+		query:     nil,
+		token:     extractorTestTokenA,
+		err:       nil,
+	},
+	{
+		name:      "mapped header missing prefix",
+		extractor: MappedHeaderExtractor("X-Auth-Token", "Token "),
+		headers:   map[string]string{"X-Auth-Token": extractorTestTokenA},
+		query:     nil,
+		token:     "",
+		err:       ErrNoTokenInRequest,
+	},
+	{
+		name: "mapped header falls through",
+		extractor: MultiExtractor{
+			MappedHeaderExtractor("X-Auth-Token", "Token "),
+			AuthorizationHeaderExtractor,
+		},
+		headers: map[string]string{"X-Auth-Token": extractorTestTokenB, "Authorization": "Bearer " + extractorTestTokenA},
+		query:   nil,
+		token:   extractorTestTokenA,
+		err:     nil,
+	},
+	{
+		name:      "simple cookie",
+		extractor: CookieExtractor{"token"},
+		headers:   map[string]string{"Cookie": "token=" + extractorTestTokenA},
+		query:     nil,
+		token:     extractorTestTokenA,
+		err:       nil,
+	},
+	{
+		name:      "cookie miss",
+		extractor: CookieExtractor{"token"},
+		headers:   map[string]string{"Cookie": "other=" + extractorTestTokenA},
+		query:     nil,
+		token:     "",
+		err:       ErrNoTokenInRequest,
+	},
+	{
+		name: "multiple extractors - cookie fallback",
+		extractor: MultiExtractor{
+			HeaderExtractor{"Foo"},
+			CookieExtractor{"token"},
+			ArgumentExtractor{"token"},
+		},
+		headers: map[string]string{"Cookie": "token=" + extractorTestTokenA},
+		query:   url.Values{"token": {extractorTestTokenB}},
+		token:   extractorTestTokenA,
+		err:     nil,
+	},
+	{
+		name:      "argument miss",
+		extractor: ArgumentExtractor{"token"},
+		headers:   map[string]string{},
+		query:     url.Values{"other": {extractorTestTokenA}},
+		token:     "",
+		err:       ErrNoTokenInRequest,
+	},
 	{
 		name:      "simple miss",
 		extractor: HeaderExtractor{"This-Header-Is-Not-Set"},
@@ -53,6 +125,46 @@ var extractorTestData = []struct {
 		token:     "",
 		err:       ErrNoTokenInRequest,
 	},
+	{
+		name:      "auth scheme - default bearer",
+		extractor: AuthorizationSchemeExtractor{},
+		headers:   map[string]string{"Authorization": "bearer " + extractorTestTokenA},
+		query:     nil,
+		token:     extractorTestTokenA,
+		err:       nil,
+	},
+	{
+		name:      "auth scheme - custom",
+		extractor: AuthorizationSchemeExtractor{"Bearer", "JWT"},
+		headers:   map[string]string{"Authorization": "JWT " + extractorTestTokenA},
+		query:     nil,
+		token:     extractorTestTokenA,
+		err:       nil,
+	},
+	{
+		name:      "auth scheme - mismatch",
+		extractor: AuthorizationSchemeExtractor{"Token"},
+		headers:   map[string]string{"Authorization": "Bearer " + extractorTestTokenA},
+		query:     nil,
+		token:     "",
+		err:       ErrUnsupportedAuthScheme,
+	},
+	{
+		name:      "auth scheme - no scheme",
+		extractor: AuthorizationSchemeExtractor{},
+		headers:   map[string]string{"Authorization": extractorTestTokenA},
+		query:     nil,
+		token:     "",
+		err:       ErrUnsupportedAuthScheme,
+	},
+	{
+		name:      "auth scheme - missing header",
+		extractor: AuthorizationSchemeExtractor{},
+		headers:   map[string]string{},
+		query:     nil,
+		token:     "",
+		err:       ErrNoTokenInRequest,
+	},
 	{
 		name:      "filter",
 		extractor: AuthorizationHeaderExtractor,
@@ -82,6 +194,38 @@ func TestExtractor(t *testing.T) {
 	}
 }
 
+func TestExtractorMultipleAuthorizationHeaders(t *testing.T) {
+	var multipleHeaderTestData = []struct {
+		name      string
+		extractor Extractor
+		values    []string
+		token     string
+		err       error
+	}{
+		{"bearer second", AuthorizationHeaderExtractor, []string{"Basic Zm9vOmJhcg==", "Bearer " + extractorTestTokenA}, extractorTestTokenA, nil},
+		{"bearer first", AuthorizationHeaderExtractor, []string{"Bearer " + extractorTestTokenA, "Bearer " + extractorTestTokenB}, extractorTestTokenA, nil},
+		{"no bearer", AuthorizationHeaderExtractor, []string{extractorTestTokenA, "Basic Zm9vOmJhcg=="}, extractorTestTokenA, nil},
+		{"scheme second", AuthorizationSchemeExtractor{"Bearer"}, []string{"Basic Zm9vOmJhcg==", "Bearer " + extractorTestTokenA}, extractorTestTokenA, nil},
+		{"scheme missing", AuthorizationSchemeExtractor{"Bearer"}, []string{"Basic Zm9vOmJhcg==", "Digest foo"}, "", ErrUnsupportedAuthScheme},
+	}
+
+	for _, data := range multipleHeaderTestData {
+		r := makeExampleRequest("GET", "/", nil, nil)
+		for _, v := range data.values {
+			r.Header.Add("Authorization", v)
+		}
+
+		token, err := data.extractor.ExtractToken(r)
+		if token != data.token {
+			t.Errorf("[%v] Expected token '%v'.  Got '%v'", data.name, data.token, token)
+			continue
+		}
+		if err != data.err {
+			t.Errorf("[%v] Expected error '%v'.  Got '%v'", data.name, data.err, err)
+		}
+	}
+}
+
 func makeExampleRequest(method, path string, headers map[string]string, urlArgs url.Values) *http.Request {
 	r, _ := http.NewRequest(method, fmt.Sprintf("%v?%v", path, urlArgs.Encode()), nil)
 	for k, v := range headers {
@@ -89,3 +233,37 @@ func makeExampleRequest(method, path string, headers map[string]string, urlArgs
 	}
 	return r
 }
+
+func TestPostExtractor(t *testing.T) {
+	var postTestData = []struct {
+		name  string
+		body  url.Values
+		query url.Values
+		token string
+		err   error
+	}{
+		{"field present", url.Values{"access_token": {extractorTestTokenA}}, nil, extractorTestTokenA, nil},
+		{"field missing", url.Values{"other": {extractorTestTokenA}}, nil, "", ErrNoTokenInRequest},
+		{"query ignored", url.Values{}, url.Values{"access_token": {extractorTestTokenA}}, "", ErrNoTokenInRequest},
+	}
+
+	for _, data := range postTestData {
+		r, _ := http.NewRequest("POST", fmt.Sprintf("/?%v", data.query.Encode()), strings.NewReader(data.body.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		token, err := PostExtractor{"access_token"}.ExtractToken(r)
+		if token != data.token {
+			t.Errorf("[%v] Expected token '%v'.  Got '%v'", data.name, data.token, token)
+			continue
+		}
+		if err != data.err {
+			t.Errorf("[%v] Expected error '%v'.  Got '%v'", data.name, data.err, err)
+			continue
+		}
+
+		// The parsed body stays available
+		if got := r.PostForm.Encode(); got != data.body.Encode() {
+			t.Errorf("[%v] Expected PostForm %v.  Got %v", data.name, data.body.Encode(), got)
+		}
+	}
+}
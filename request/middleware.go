@@ -0,0 +1,60 @@
+//go:build go1.7
+// +build go1.7
+
+package request
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+type contextKey struct {
+	name string
+}
+
+// Context key under which Middleware stores the validated *jwt.Token.
+// Prefer FromContext to reading it directly.
+var TokenContextKey = &contextKey{"jwt-token"}
+
+// Returns a copy of ctx carrying token.  Used by Middleware.
+func NewContext(ctx context.Context, token *jwt.Token) context.Context {
+	return context.WithValue(ctx, TokenContextKey, token)
+}
+
+// Returns the token stored in ctx by Middleware, if any
+func FromContext(ctx context.Context) (*jwt.Token, bool) {
+	token, ok := ctx.Value(TokenContextKey).(*jwt.Token)
+	return token, ok
+}
+
+// Called by Middleware when a request has a missing or invalid token
+type ErrorHandler func(w http.ResponseWriter, req *http.Request, err error)
+
+// Wraps an http.Handler so that each request must carry a valid token.  The
+// token is extracted and parsed as with ParseFromRequest and, if valid, made
+// available to the wrapped handler through FromContext.
+//
+// If the token is missing or invalid, onError is called and the wrapped
+// handler is not.  A nil onError responds with 401 Unauthorized.
+func Middleware(extractor Extractor, keyFunc jwt.Keyfunc, onError ErrorHandler, options ...ParseFromRequestOption) func(http.Handler) http.Handler {
+	if onError == nil {
+		onError = unauthorized
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			token, err := ParseFromRequest(req, extractor, keyFunc, options...)
+			if err != nil {
+				onError(w, req, err)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), token)))
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, req *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
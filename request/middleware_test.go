@@ -0,0 +1,80 @@
+//go:build go1.7
+// +build go1.7
+
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/dgrijalva/jwt-go/test"
+)
+
+func TestMiddleware(t *testing.T) {
+	privateKey := test.LoadRSAPrivateKeyFromDisk("../test/sample_key")
+	publicKey := test.LoadRSAPublicKeyFromDisk("../test/sample_key.pub")
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}
+
+	handler := Middleware(AuthorizationHeaderExtractor, keyfunc, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := FromContext(r.Context())
+		if !ok {
+			t.Errorf("Token missing from request context")
+			return
+		}
+		fmt.Fprint(w, token.Claims.(jwt.MapClaims)["foo"])
+	}))
+
+	var middlewareTestData = []struct {
+		name   string
+		header string
+		status int
+		body   string
+	}{
+		{"valid token", "Bearer " + test.MakeSampleToken(jwt.MapClaims{"foo": "bar"}, privateKey), http.StatusOK, "bar"},
+		{"missing token", "", http.StatusUnauthorized, ""},
+		{"invalid token", "Bearer not.a.token", http.StatusUnauthorized, ""},
+	}
+
+	for _, data := range middlewareTestData {
+		req := httptest.NewRequest("GET", "/", nil)
+		if data.header != "" {
+			req.Header.Set("Authorization", data.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != data.status {
+			t.Errorf("[%v] Expected status %v.  Got %v", data.name, data.status, rec.Code)
+		}
+		if data.status == http.StatusOK && rec.Body.String() != data.body {
+			t.Errorf("[%v] Expected body %q.  Got %q", data.name, data.body, rec.Body.String())
+		}
+	}
+}
+
+func TestMiddlewareErrorHandler(t *testing.T) {
+	var handled error
+	onError := func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusForbidden)
+	}
+
+	handler := Middleware(AuthorizationHeaderExtractor, nil, onError)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Wrapped handler called without a token")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	if handled != ErrNoTokenInRequest {
+		t.Errorf("Expected ErrNoTokenInRequest.  Got %v", handled)
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %v.  Got %v", http.StatusForbidden, rec.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"net/http"
 	"strings"
 )
 
@@ -15,11 +16,69 @@ func stripBearerPrefixFromTokenString(tok string) (string, error) {
 
 // Extract bearer token from Authorization header
 // Uses PostExtractionFilter to strip "Bearer " prefix from header
+// If the request has several Authorization headers, as can happen behind
+// proxies, the first bearer token among them is used.
 var AuthorizationHeaderExtractor = &PostExtractionFilter{
-	HeaderExtractor{"Authorization"},
+	authorizationHeaderExtractor{},
 	stripBearerPrefixFromTokenString,
 }
 
+// Returns the first Authorization header using the Bearer scheme, falling
+// back to the first Authorization header
+type authorizationHeaderExtractor struct{}
+
+func (authorizationHeaderExtractor) ExtractToken(req *http.Request) (string, error) {
+	values := req.Header["Authorization"]
+	for _, ah := range values {
+		if len(ah) > 6 && strings.ToUpper(ah[0:7]) == "BEARER " {
+			return ah, nil
+		}
+	}
+	for _, ah := range values {
+		if ah != "" {
+			return ah, nil
+		}
+	}
+	return "", ErrNoTokenInRequest
+}
+
+// Extracts a token from the Authorization header, accepting only the listed
+// schemes (such as "Bearer", "Token" or "JWT").  Schemes are compared
+// case-insensitively.  If the header uses any other scheme, ErrUnsupportedAuthScheme
+// is returned rather than treating the request as having no token.
+// An empty list accepts "Bearer".
+// If the request has several Authorization headers, the first one with an
+// accepted scheme is used.
+type AuthorizationSchemeExtractor []string
+
+func (e AuthorizationSchemeExtractor) ExtractToken(req *http.Request) (string, error) {
+	schemes := []string(e)
+	if len(schemes) == 0 {
+		schemes = []string{"Bearer"}
+	}
+
+	found := false
+	for _, ah := range req.Header["Authorization"] {
+		if ah == "" {
+			continue
+		}
+		found = true
+
+		if i := strings.IndexByte(ah, ' '); i > 0 {
+			for _, scheme := range schemes {
+				if strings.EqualFold(ah[:i], scheme) {
+					return strings.TrimSpace(ah[i+1:]), nil
+				}
+			}
+		}
+	}
+
+	if !found {
+		return "", ErrNoTokenInRequest
+	}
+	return "", ErrUnsupportedAuthScheme
+}
+
 // Extractor for OAuth2 access tokens.  Looks in 'Authorization'
 // header then 'access_token' argument for a token.
 var OAuth2Extractor = &MultiExtractor{
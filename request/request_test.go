@@ -101,3 +101,77 @@ func TestParseRequest(t *testing.T) {
 		}
 	}
 }
+
+type customClaims struct {
+	Tenant string   `json:"tenant"`
+	Scopes []string `json:"scopes"`
+	jwt.StandardClaims
+}
+
+func TestParseRequestWithCustomClaims(t *testing.T) {
+	privateKey := test.LoadRSAPrivateKeyFromDisk("../test/sample_key")
+	publicKey := test.LoadRSAPublicKeyFromDisk("../test/sample_key.pub")
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}
+
+	expected := &customClaims{
+		Tenant:         "acme",
+		Scopes:         []string{"read", "admin"},
+		StandardClaims: jwt.StandardClaims{Subject: "alice"},
+	}
+	tokenString := test.MakeSampleToken(expected, privateKey)
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+tokenString)
+
+	claims := &customClaims{}
+	token, err := ParseFromRequestWithClaims(r, AuthorizationHeaderExtractor, claims, keyfunc)
+	if err != nil || !token.Valid {
+		t.Fatalf("Error while verifying token: %v", err)
+	}
+	if token.Claims != claims || !reflect.DeepEqual(claims, expected) {
+		t.Errorf("Claims mismatch. Expecting: %v  Got: %v", expected, token.Claims)
+	}
+
+	// The same through the WithClaims option
+	claims = &customClaims{}
+	if _, err = ParseFromRequest(r, AuthorizationHeaderExtractor, keyfunc, WithClaims(claims)); err != nil || !reflect.DeepEqual(claims, expected) {
+		t.Errorf("Claims mismatch with WithClaims. Expecting: %v  Got: %v (%v)", expected, claims, err)
+	}
+}
+
+func TestParseRequestMaxTokenLength(t *testing.T) {
+	privateKey := test.LoadRSAPrivateKeyFromDisk("../test/sample_key")
+	publicKey := test.LoadRSAPublicKeyFromDisk("../test/sample_key.pub")
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}
+
+	tokenString := test.MakeSampleToken(jwt.MapClaims{"foo": "bar"}, privateKey)
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+tokenString)
+
+	parser := &jwt.Parser{MaxTokenLength: len(tokenString) - 1}
+	_, err := ParseFromRequest(r, AuthorizationHeaderExtractor, keyfunc, WithParser(parser))
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorMalformed {
+		t.Errorf("Expected malformed error for oversized token.  Got %v", err)
+	}
+}
+
+func TestParseRequestMultipleAuthorizationHeaders(t *testing.T) {
+	privateKey := test.LoadRSAPrivateKeyFromDisk("../test/sample_key")
+	publicKey := test.LoadRSAPublicKeyFromDisk("../test/sample_key.pub")
+	keyfunc := func(*jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}
+
+	// A proxy's own credentials ahead of the bearer token
+	tokenString := test.MakeSampleToken(jwt.MapClaims{"foo": "bar"}, privateKey)
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Add("Authorization", "Basic Zm9vOmJhcg==")
+	r.Header.Add("Authorization", "Bearer "+tokenString)
+
+	if token, err := ParseFromRequest(r, AuthorizationHeaderExtractor, keyfunc); err != nil || !token.Valid {
+		t.Errorf("Error while verifying token: %v", err)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"hash"
+	"io"
 )
 
 // Implements the RSA family of signing methods signing methods
-// Expects *rsa.PrivateKey for signing and *rsa.PublicKey for validation
+// Expects *rsa.PrivateKey, or a crypto.Signer with an RSA key, for signing and
+// *rsa.PublicKey for validation
 type SigningMethodRSA struct {
 	Name string
 	Hash crypto.Hash
@@ -44,9 +47,24 @@ func (m *SigningMethodRSA) Alg() string {
 	return m.Name
 }
 
+// Implements HashFuncer.  Also used by the RSAPSS methods.
+func (m *SigningMethodRSA) HashFunc() crypto.Hash {
+	return m.Hash
+}
+
+// Also used by the RSAPSS methods, which take the same keys
+func (m *SigningMethodRSA) checkVerificationKey(key interface{}) (string, bool) {
+	_, ok := key.(*rsa.PublicKey)
+	return "*rsa.PublicKey", ok
+}
+
 // Implements the Verify method from SigningMethod
 // For this signing method, must be an *rsa.PublicKey structure.
 func (m *SigningMethodRSA) Verify(signingString, signature string, key interface{}) error {
+	if isNilKey(key) {
+		return ErrNilKey
+	}
+
 	var err error
 
 	// Decode the signature
@@ -74,28 +92,58 @@ func (m *SigningMethodRSA) Verify(signingString, signature string, key interface
 }
 
 // Implements the Sign method from SigningMethod
-// For this signing method, must be an *rsa.PrivateKey structure.
+// For this signing method, must be an *rsa.PrivateKey structure, or a
+// crypto.Signer whose public key is an *rsa.PublicKey, such as an HSM key.
 func (m *SigningMethodRSA) Sign(signingString string, key interface{}) (string, error) {
-	var rsaKey *rsa.PrivateKey
-	var ok bool
+	return m.SignWithRand(signingString, key, rand.Reader)
+}
+
+// Like Sign, but hands random to rsa.SignPKCS1v15 instead of crypto/rand.
+// PKCS #1 v1.5 signatures are deterministic regardless; this exists so all
+// the RSA and ECDSA methods can be driven the same way.
+func (m *SigningMethodRSA) SignWithRand(signingString string, key interface{}, random io.Reader) (string, error) {
+	hasher, err := m.signingHash(key)
+	if err != nil {
+		return "", err
+	}
+	hasher.Write([]byte(signingString))
+	return m.signDigest(random, hasher.Sum(nil), key)
+}
+
+func (m *SigningMethodRSA) signingHash(key interface{}) (hash.Hash, error) {
+	if isNilKey(key) {
+		return nil, ErrNilKey
+	}
 
 	// Validate type of key
-	if rsaKey, ok = key.(*rsa.PrivateKey); !ok {
-		return "", ErrInvalidKey
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+	case crypto.Signer:
+		if _, ok := k.Public().(*rsa.PublicKey); !ok {
+			return nil, ErrInvalidKey
+		}
+	default:
+		return nil, ErrInvalidKey
 	}
 
 	// Create the hasher
 	if !m.Hash.Available() {
-		return "", ErrHashUnavailable
+		return nil, ErrHashUnavailable
 	}
+	return m.Hash.New(), nil
+}
 
-	hasher := m.Hash.New()
-	hasher.Write([]byte(signingString))
-
-	// Sign the string and return the encoded bytes
-	if sigBytes, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, m.Hash, hasher.Sum(nil)); err == nil {
-		return EncodeSegment(sigBytes), nil
+// Sign the digest and return the encoded bytes
+func (m *SigningMethodRSA) signDigest(random io.Reader, digest []byte, key interface{}) (string, error) {
+	var sigBytes []byte
+	var err error
+	if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+		sigBytes, err = rsa.SignPKCS1v15(random, rsaKey, m.Hash, digest)
 	} else {
+		sigBytes, err = key.(crypto.Signer).Sign(random, digest, m.Hash)
+	}
+	if err != nil {
 		return "", err
 	}
+	return EncodeSegment(sigBytes), nil
 }
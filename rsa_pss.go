@@ -6,6 +6,8 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
+	"hash"
+	"io"
 )
 
 // Implements the RSAPSS family of signing methods signing methods
@@ -82,6 +84,10 @@ func init() {
 // Implements the Verify method from SigningMethod
 // For this verify method, key must be an rsa.PublicKey struct
 func (m *SigningMethodRSAPSS) Verify(signingString, signature string, key interface{}) error {
+	if isNilKey(key) {
+		return ErrNilKey
+	}
+
 	var err error
 
 	// Decode the signature
@@ -116,27 +122,59 @@ func (m *SigningMethodRSAPSS) Verify(signingString, signature string, key interf
 // Implements the Sign method from SigningMethod
 // For this signing method, key must be an rsa.PrivateKey struct
 func (m *SigningMethodRSAPSS) Sign(signingString string, key interface{}) (string, error) {
-	var rsaKey *rsa.PrivateKey
+	return m.SignWithRand(signingString, key, rand.Reader)
+}
+
+// Like Sign, but draws the salt from random instead of crypto/rand.
+//
+// Since Go 1.26, crypto/rsa ignores random and uses crypto/rand unless the
+// program runs with GODEBUG=cryptocustomrand=1, so a fixed reader won't give
+// reproducible signatures.  Use testing/cryptotest.SetGlobalRandom in tests.
+func (m *SigningMethodRSAPSS) SignWithRand(signingString string, key interface{}, random io.Reader) (string, error) {
+	hasher, err := m.signingHash(key)
+	if err != nil {
+		return "", err
+	}
+	hasher.Write([]byte(signingString))
+	return m.signDigest(random, hasher.Sum(nil), key)
+}
+
+func (m *SigningMethodRSAPSS) signingHash(key interface{}) (hash.Hash, error) {
+	if isNilKey(key) {
+		return nil, ErrNilKey
+	}
 
 	switch k := key.(type) {
 	case *rsa.PrivateKey:
-		rsaKey = k
+	case crypto.Signer:
+		if _, ok := k.Public().(*rsa.PublicKey); !ok {
+			return nil, ErrInvalidKeyType
+		}
 	default:
-		return "", ErrInvalidKeyType
+		return nil, ErrInvalidKeyType
 	}
 
 	// Create the hasher
 	if !m.Hash.Available() {
-		return "", ErrHashUnavailable
+		return nil, ErrHashUnavailable
 	}
+	return m.Hash.New(), nil
+}
 
-	hasher := m.Hash.New()
-	hasher.Write([]byte(signingString))
-
-	// Sign the string and return the encoded bytes
-	if sigBytes, err := rsa.SignPSS(rand.Reader, rsaKey, m.Hash, hasher.Sum(nil), m.Options); err == nil {
-		return EncodeSegment(sigBytes), nil
+// Sign the digest and return the encoded bytes
+func (m *SigningMethodRSAPSS) signDigest(random io.Reader, digest []byte, key interface{}) (string, error) {
+	var sigBytes []byte
+	var err error
+	if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+		sigBytes, err = rsa.SignPSS(random, rsaKey, m.Hash, digest, m.Options)
 	} else {
+		// The signer takes the hash from the options
+		opts := *m.Options
+		opts.Hash = m.Hash
+		sigBytes, err = key.(crypto.Signer).Sign(random, digest, &opts)
+	}
+	if err != nil {
 		return "", err
 	}
+	return EncodeSegment(sigBytes), nil
 }
@@ -3,6 +3,7 @@
 package jwt_test
 
 import (
+	"crypto/rand"
 	"crypto/rsa"
 	"io/ioutil"
 	"strings"
@@ -130,6 +131,47 @@ func TestRSAPSSSaltLengthCompatibility(t *testing.T) {
 	}
 }
 
+func TestRSAPSSSignVerifyGeneratedKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, method := range []*jwt.SigningMethodRSAPSS{jwt.SigningMethodPS256, jwt.SigningMethodPS384, jwt.SigningMethodPS512} {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"foo": "bar"})
+		tokenString, err := token.SignedString(privateKey)
+		if err != nil {
+			t.Errorf("[%v] Error signing token: %v", method.Alg(), err)
+			continue
+		}
+
+		parts := strings.Split(tokenString, ".")
+		if err := method.Verify(strings.Join(parts[0:2], "."), parts[2], &privateKey.PublicKey); err != nil {
+			t.Errorf("[%v] Error while verifying key: %v", method.Alg(), err)
+		}
+	}
+}
+
+func TestRSAPSSCrossAlgorithm(t *testing.T) {
+	var crossTestData = []struct {
+		signer   jwt.SigningMethod
+		verifier jwt.SigningMethod
+	}{
+		{jwt.SigningMethodPS256, jwt.SigningMethodRS256},
+		{jwt.SigningMethodRS256, jwt.SigningMethodPS256},
+		{jwt.SigningMethodPS384, jwt.SigningMethodRS384},
+		{jwt.SigningMethodRS384, jwt.SigningMethodPS384},
+		{jwt.SigningMethodPS512, jwt.SigningMethodRS512},
+		{jwt.SigningMethodRS512, jwt.SigningMethodPS512},
+	}
+
+	for _, data := range crossTestData {
+		if verify(data.verifier, makeToken(data.signer)) {
+			t.Errorf("%v signature should not verify as %v", data.signer.Alg(), data.verifier.Alg())
+		}
+	}
+}
+
 func makeToken(method jwt.SigningMethod) string {
 	token := jwt.NewWithClaims(method, jwt.StandardClaims{
 		Issuer:   "example",
@@ -1,6 +1,8 @@
 package jwt_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"github.com/dgrijalva/jwt-go"
 	"io/ioutil"
 	"strings"
@@ -112,6 +114,27 @@ func TestRSAWithPreParsedPrivateKey(t *testing.T) {
 	}
 }
 
+func TestRSASignVerifyGeneratedKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, method := range []*jwt.SigningMethodRSA{jwt.SigningMethodRS256, jwt.SigningMethodRS384, jwt.SigningMethodRS512} {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"foo": "bar"})
+		tokenString, err := token.SignedString(privateKey)
+		if err != nil {
+			t.Errorf("[%v] Error signing token: %v", method.Alg(), err)
+			continue
+		}
+
+		parts := strings.Split(tokenString, ".")
+		if err := method.Verify(strings.Join(parts[0:2], "."), parts[2], &privateKey.PublicKey); err != nil {
+			t.Errorf("[%v] Error while verifying key: %v", method.Alg(), err)
+		}
+	}
+}
+
 func TestRSAKeyParsing(t *testing.T) {
 	key, _ := ioutil.ReadFile("test/sample_key")
 	secureKey, _ := ioutil.ReadFile("test/privateSecure.pem")
@@ -1,6 +1,12 @@
 package jwt
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"hash"
+	"io"
+	"sort"
 	"sync"
 )
 
@@ -14,6 +20,48 @@ type SigningMethod interface {
 	Alg() string                                                   // returns the alg identifier for this method (example: 'HS256')
 }
 
+// Implemented by the built in signing methods to report the hash applied to
+// the signing string, such as crypto.SHA256 for HS256, RS256 and ES256.
+// Methods that don't hash it first, "none" and EdDSA, return crypto.Hash(0).
+// The name matches crypto.SignerOpts, since the Hash field is taken.
+type HashFuncer interface {
+	HashFunc() crypto.Hash
+}
+
+// Implemented by the built in signing methods, so Parse can reject a key of
+// the wrong type before verifying with it.  Returns the type expected.
+type verificationKeyChecker interface {
+	checkVerificationKey(key interface{}) (expected string, ok bool)
+}
+
+// Implemented by the built in methods whose signature depends only on a hash
+// of the signing string, so WriteSignedString can hash it as it's written.
+// signingHash checks key before anything is written.
+type digestSigner interface {
+	signingHash(key interface{}) (hash.Hash, error)
+	signDigest(random io.Reader, digest []byte, key interface{}) (string, error)
+}
+
+// Reports whether key is nil, including a typed nil of the key types the
+// built in methods take, which would otherwise panic or sign with no key
+func isNilKey(key interface{}) bool {
+	switch k := key.(type) {
+	case nil:
+		return true
+	case []byte:
+		return k == nil
+	case *rsa.PublicKey:
+		return k == nil
+	case *rsa.PrivateKey:
+		return k == nil
+	case *ecdsa.PublicKey:
+		return k == nil
+	case *ecdsa.PrivateKey:
+		return k == nil
+	}
+	return false
+}
+
 // Register the "alg" name and a factory function for signing method.
 // This is typically done during init() in the method's implementation
 func RegisterSigningMethod(alg string, f func() SigningMethod) {
@@ -23,6 +71,16 @@ func RegisterSigningMethod(alg string, f func() SigningMethod) {
 	signingMethods[alg] = f
 }
 
+// Remove the signing method registered for "alg", so tokens using it are
+// rejected by Parse.  Use this to lock down algorithms globally, for
+// example "none", rather than relying on every Parser setting ValidMethods.
+func UnregisterSigningMethod(alg string) {
+	signingMethodLock.Lock()
+	defer signingMethodLock.Unlock()
+
+	delete(signingMethods, alg)
+}
+
 // Get a signing method from an "alg" string
 func GetSigningMethod(alg string) (method SigningMethod) {
 	signingMethodLock.RLock()
@@ -33,3 +91,64 @@ func GetSigningMethod(alg string) (method SigningMethod) {
 	}
 	return
 }
+
+// Get the "alg" names of all registered signing methods, sorted
+func GetSigningMethods() []string {
+	signingMethodLock.RLock()
+	defer signingMethodLock.RUnlock()
+
+	algs := make([]string, 0, len(signingMethods))
+	for alg := range signingMethods {
+		algs = append(algs, alg)
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+// Get the "alg" names of the registered public key methods, sorted.  These
+// are the built in RSA, RSA-PSS, ECDSA and EdDSA methods, so
+// WithValidMethods(AsymmetricMethods()) rules out HMAC, "none", and any
+// custom method.
+func AsymmetricMethods() []string {
+	return signingMethodsWhere(func(method SigningMethod) bool {
+		_, builtin := method.(verificationKeyChecker)
+		_, hmac := method.(*SigningMethodHMAC)
+		return builtin && !hmac
+	})
+}
+
+// Get the "alg" names of the registered HMAC methods, sorted
+func SymmetricMethods() []string {
+	return signingMethodsWhere(func(method SigningMethod) bool {
+		_, hmac := method.(*SigningMethodHMAC)
+		return hmac
+	})
+}
+
+func signingMethodsWhere(match func(SigningMethod) bool) []string {
+	signingMethodLock.RLock()
+	defer signingMethodLock.RUnlock()
+
+	var algs []string
+	for alg, f := range signingMethods {
+		if match(f()) {
+			algs = append(algs, alg)
+		}
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+// Verify signature against each of keys in turn, as during key rotation
+// when the token doesn't say which key signed it.  Returns the index of the
+// first key that verifies.  If none do, returns -1 and the error from the
+// last key tried.
+func VerifyWithKeys(signingString, signature string, method SigningMethod, keys []interface{}) (int, error) {
+	err := ErrInvalidKey
+	for i, key := range keys {
+		if err = method.Verify(signingString, signature, key); err == nil {
+			return i, nil
+		}
+	}
+	return -1, err
+}
@@ -0,0 +1,375 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// A fake signing method that signs everything with the same value
+type testSigningMethod struct {
+	alg      string
+	verified bool
+}
+
+func (m *testSigningMethod) Alg() string {
+	return m.alg
+}
+
+func (m *testSigningMethod) Sign(signingString string, key interface{}) (string, error) {
+	return "c2lnbmVk", nil
+}
+
+func (m *testSigningMethod) Verify(signingString, signature string, key interface{}) error {
+	m.verified = true
+	if signature != "c2lnbmVk" {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+func TestRegisterSigningMethod(t *testing.T) {
+	method := &testSigningMethod{alg: "TEST-REGISTER"}
+	jwt.RegisterSigningMethod(method.Alg(), func() jwt.SigningMethod {
+		return method
+	})
+
+	if m := jwt.GetSigningMethod("TEST-REGISTER"); m != method {
+		t.Fatalf("GetSigningMethod returned %v", m)
+	}
+
+	tokenString, err := jwt.New(method).SignedString(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return nil, nil })
+	if err != nil {
+		t.Fatalf("Error while parsing token: %v", err)
+	}
+	if token.Method != method || !method.verified {
+		t.Errorf("Parse did not dispatch to the registered method")
+	}
+}
+
+func TestSigningMethodRegistryConcurrency(t *testing.T) {
+	tokenString, err := jwt.New(jwt.SigningMethodHS256).SignedString(hmacTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run with -race to check registration doesn't race with lookups from Parse
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			method := &testSigningMethod{alg: fmt.Sprintf("TEST-CONCURRENT-%v", i)}
+			jwt.RegisterSigningMethod(method.Alg(), func() jwt.SigningMethod {
+				return method
+			})
+			jwt.UnregisterSigningMethod(method.Alg())
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return hmacTestKey, nil }); err != nil {
+				t.Errorf("Error while parsing token: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestVerifyWithKeys(t *testing.T) {
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"}).SignedString([]byte("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(tokenString, ".")
+	signingString := strings.Join(parts[0:2], ".")
+
+	keys := []interface{}{"wrong type", []byte("first"), []byte("second")}
+	if i, err := jwt.VerifyWithKeys(signingString, parts[2], jwt.SigningMethodHS256, keys); i != 2 || err != nil {
+		t.Errorf("Expected the third key to verify.  Got %v, %v", i, err)
+	}
+
+	if i, err := jwt.VerifyWithKeys(signingString, parts[2], jwt.SigningMethodHS256, keys[:2]); i != -1 || err != jwt.ErrSignatureInvalid {
+		t.Errorf("Expected no key to verify.  Got %v, %v", i, err)
+	}
+
+	if i, err := jwt.VerifyWithKeys(signingString, parts[2], jwt.SigningMethodHS256, nil); i != -1 || err != jwt.ErrInvalidKey {
+		t.Errorf("Expected ErrInvalidKey without keys.  Got %v, %v", i, err)
+	}
+}
+
+func TestGetSigningMethods(t *testing.T) {
+	registered := map[string]bool{}
+	for _, alg := range jwt.GetSigningMethods() {
+		registered[alg] = true
+	}
+
+	for _, alg := range []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "none"} {
+		if !registered[alg] {
+			t.Errorf("[%v] Missing from GetSigningMethods", alg)
+		}
+	}
+
+	jwt.RegisterSigningMethod("TEST-LIST", func() jwt.SigningMethod {
+		return &testSigningMethod{alg: "TEST-LIST"}
+	})
+	found := false
+	for _, alg := range jwt.GetSigningMethods() {
+		found = found || alg == "TEST-LIST"
+	}
+	if !found {
+		t.Errorf("Registered method missing from GetSigningMethods")
+	}
+}
+
+func TestUnregisterSigningMethod(t *testing.T) {
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"}).SignedString(hmacTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFunc := func(*jwt.Token) (interface{}, error) { return hmacTestKey, nil }
+	if _, err := jwt.Parse(tokenString, keyFunc); err != nil {
+		t.Fatalf("Error while verifying token: %v", err)
+	}
+
+	jwt.UnregisterSigningMethod("HS256")
+	defer jwt.RegisterSigningMethod("HS256", func() jwt.SigningMethod {
+		return jwt.SigningMethodHS256
+	})
+
+	if m := jwt.GetSigningMethod("HS256"); m != nil {
+		t.Errorf("GetSigningMethod returned unregistered method %v", m)
+	}
+	_, err = jwt.Parse(tokenString, keyFunc)
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorUnverifiable {
+		t.Errorf("Expected ValidationErrorUnverifiable after unregistering.  Got %v", err)
+	}
+}
+
+func TestMethodPresets(t *testing.T) {
+	asymmetric := jwt.AsymmetricMethods()
+	for _, alg := range []string{"ES256", "ES384", "ES512", "PS256", "PS384", "PS512", "RS256", "RS384", "RS512"} {
+		if !containsString(asymmetric, alg) {
+			t.Errorf("Expected %v in AsymmetricMethods: %v", alg, asymmetric)
+		}
+	}
+	if symmetric := jwt.SymmetricMethods(); !reflect.DeepEqual(symmetric, []string{"HS256", "HS384", "HS512"}) {
+		t.Errorf("Unexpected SymmetricMethods: %v", symmetric)
+	}
+	if containsString(asymmetric, "none") || containsString(asymmetric, "HS256") {
+		t.Errorf("Unexpected none or HMAC in AsymmetricMethods: %v", asymmetric)
+	}
+
+	// The presets follow the registry, leaving out methods they can't classify
+	custom := &testSigningMethod{alg: "TEST-PRESET"}
+	jwt.RegisterSigningMethod(custom.Alg(), func() jwt.SigningMethod { return custom })
+	jwt.RegisterSigningMethod("HS256-COPY", func() jwt.SigningMethod { return jwt.SigningMethodHS256 })
+	defer jwt.UnregisterSigningMethod(custom.Alg())
+	defer jwt.UnregisterSigningMethod("HS256-COPY")
+	if !containsString(jwt.SymmetricMethods(), "HS256-COPY") {
+		t.Errorf("Expected a newly registered method in SymmetricMethods")
+	}
+	if containsString(jwt.AsymmetricMethods(), custom.Alg()) || containsString(jwt.SymmetricMethods(), custom.Alg()) {
+		t.Errorf("Expected a custom method in neither preset")
+	}
+}
+
+func TestMethodPresets_Parse(t *testing.T) {
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"}).SignedString(hmacTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFunc := func(*jwt.Token) (interface{}, error) { return hmacTestKey, nil }
+
+	_, err = jwt.Parse(tokenString, keyFunc, jwt.WithValidMethods(jwt.AsymmetricMethods()))
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorSignatureInvalid {
+		t.Errorf("Expected HMAC token to be rejected under the asymmetric preset.  Got %v", err)
+	}
+
+	if _, err = jwt.Parse(tokenString, keyFunc, jwt.WithValidMethods(jwt.SymmetricMethods())); err != nil {
+		t.Errorf("Error while verifying token: %v", err)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHashFunc(t *testing.T) {
+	expected := map[string]crypto.Hash{
+		"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+		"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+		"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+		"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+		"EdDSA": 0, "none": 0,
+	}
+
+	for _, alg := range jwt.GetSigningMethods() {
+		hash, known := expected[alg]
+		if !known {
+			// Registered by another test
+			continue
+		}
+		h, ok := jwt.GetSigningMethod(alg).(jwt.HashFuncer)
+		if !ok {
+			t.Errorf("[%v] Expected the method to implement HashFuncer", alg)
+			continue
+		}
+		if h.HashFunc() != hash {
+			t.Errorf("[%v] Expected %v.  Got %v", alg, hash, h.HashFunc())
+		}
+	}
+}
+
+// A crypto.Signer hiding the key behind it, as an HSM or KMS would
+type opaqueSigner struct {
+	signer crypto.Signer
+}
+
+func (s opaqueSigner) Public() crypto.PublicKey {
+	return s.signer.Public()
+}
+
+func (s opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.signer.Sign(rand, digest, opts)
+}
+
+func TestCryptoSigner(t *testing.T) {
+	rsaKey := generateTestRSAKey(t)
+	ec256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+
+	var signerTestData = []struct {
+		name   string
+		method jwt.SigningMethod
+		key    crypto.Signer
+		err    error
+	}{
+		{"RS256", jwt.SigningMethodRS256, rsaKey, nil},
+		{"RS512", jwt.SigningMethodRS512, rsaKey, nil},
+		{"PS256", jwt.SigningMethodPS256, rsaKey, nil},
+		{"PS512", jwt.SigningMethodPS512, rsaKey, nil},
+		{"ES256", jwt.SigningMethodES256, ec256Key, nil},
+		{"ES384", jwt.SigningMethodES384, ec384Key, nil},
+		{"RS256 with ECDSA signer", jwt.SigningMethodRS256, ec256Key, jwt.ErrInvalidKey},
+		{"PS256 with ECDSA signer", jwt.SigningMethodPS256, ec256Key, jwt.ErrInvalidKeyType},
+		{"ES256 with RSA signer", jwt.SigningMethodES256, rsaKey, jwt.ErrInvalidKeyType},
+		{"ES256 with P-384 signer", jwt.SigningMethodES256, ec384Key, jwt.ErrInvalidKey},
+	}
+
+	for _, data := range signerTestData {
+		signingString := "eyJhbGciOiJub25lIn0.eyJmb28iOiJiYXIifQ"
+		sig, err := data.method.Sign(signingString, opaqueSigner{data.key})
+		if err != data.err {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, data.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if err = data.method.Verify(signingString, sig, data.key.Public()); err != nil {
+			t.Errorf("[%v] Error while verifying signature: %v", data.name, err)
+		}
+	}
+}
+
+// A crypto.Signer returning a fixed ASN.1 signature, as a faulty HSM might
+type fixedSigner struct {
+	public crypto.PublicKey
+	r, s   *big.Int
+}
+
+func (s fixedSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s fixedSigner) Sign(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+	return asn1.Marshal(struct{ R, S *big.Int }{s.r, s.s})
+}
+
+func TestCryptoSignerOutOfRange(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	n := key.Curve.Params().N
+	tooLong := new(big.Int).Lsh(big.NewInt(1), 300)
+
+	var rangeTestData = []struct {
+		name string
+		r, s *big.Int
+	}{
+		{"r longer than the curve", tooLong, big.NewInt(1)},
+		{"s longer than the curve", big.NewInt(1), tooLong},
+		{"r equal to the order", n, big.NewInt(1)},
+		{"zero s", big.NewInt(1), big.NewInt(0)},
+		{"negative r", big.NewInt(-1), big.NewInt(1)},
+	}
+
+	for _, data := range rangeTestData {
+		_, err := jwt.SigningMethodES256.Sign("eyJhbGciOiJub25lIn0.eyJmb28iOiJiYXIifQ", fixedSigner{&key.PublicKey, data.r, data.s})
+		if err == nil {
+			t.Errorf("[%v] Expected an error for a signature out of range", data.name)
+		}
+	}
+}
+
+func TestNilKey(t *testing.T) {
+	signingString := "eyJhbGciOiJub25lIn0.eyJmb28iOiJiYXIifQ"
+	for _, alg := range append(jwt.AsymmetricMethods(), jwt.SymmetricMethods()...) {
+		method := jwt.GetSigningMethod(alg)
+		if _, err := method.Sign(signingString, nil); err != jwt.ErrNilKey {
+			t.Errorf("[%v] Expected ErrNilKey while signing.  Got %v", alg, err)
+		}
+		if err := method.Verify(signingString, "c2lnbmVk", nil); err != jwt.ErrNilKey {
+			t.Errorf("[%v] Expected ErrNilKey while verifying.  Got %v", alg, err)
+		}
+	}
+
+	// Typed nils must not panic either
+	var typedNilTestData = []struct {
+		method jwt.SigningMethod
+		key    interface{}
+	}{
+		{jwt.SigningMethodHS256, []byte(nil)},
+		{jwt.SigningMethodRS256, (*rsa.PublicKey)(nil)},
+		{jwt.SigningMethodRS256, (*rsa.PrivateKey)(nil)},
+		{jwt.SigningMethodPS256, (*rsa.PublicKey)(nil)},
+		{jwt.SigningMethodPS256, (*rsa.PrivateKey)(nil)},
+		{jwt.SigningMethodES256, (*ecdsa.PublicKey)(nil)},
+		{jwt.SigningMethodES256, (*ecdsa.PrivateKey)(nil)},
+	}
+	for _, data := range typedNilTestData {
+		if _, err := data.method.Sign(signingString, data.key); err != jwt.ErrNilKey {
+			t.Errorf("[%v %T] Expected ErrNilKey while signing.  Got %v", data.method.Alg(), data.key, err)
+		}
+		if err := data.method.Verify(signingString, "c2lnbmVk", data.key); err != jwt.ErrNilKey {
+			t.Errorf("[%v %T] Expected ErrNilKey while verifying.  Got %v", data.method.Alg(), data.key, err)
+		}
+	}
+
+	// A Keyfunc returning nil, nil gets the same error from Parse
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"foo": "bar"}).SignedString(hmacTestKey)
+	_, err := jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) { return nil, nil })
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Inner != jwt.ErrNilKey {
+		t.Errorf("Expected ErrNilKey from Parse.  Got %v", err)
+	}
+}
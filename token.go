@@ -1,9 +1,16 @@
 package jwt
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -12,6 +19,19 @@ import (
 // server uses a different time zone than your tokens.
 var TimeFunc = time.Now
 
+// The JSON functions used to encode the header and claims when signing, and
+// to decode them when parsing, including by the Parser's payload checks and
+// the JSON methods of ExtendedClaims and ClaimStrings.  Replace them with a
+// compatible, faster implementation if you like.  A few paths need
+// encoding/json's Decoder and always use it: decoding claims for a Parser with
+// UseJSONNumber set, reading exp for RequireExpiry, and DisallowDuplicateKeys.
+// Either way, data after the claims object makes a token malformed, where
+// releases before these hooks ignored it.
+var (
+	Marshal   = json.Marshal
+	Unmarshal = json.Unmarshal
+)
+
 // Parse methods use this callback function to supply
 // the key for verification.  The function receives the parsed,
 // but unverified Token.  This allows you to use properties in the
@@ -22,11 +42,14 @@ type Keyfunc func(*Token) (interface{}, error)
 // creating or parsing/verifying a token.
 type Token struct {
 	Raw       string                 // The raw token.  Populated when you Parse a token
+	Parts     []string               // The encoded segments of the raw token.  Populated when you Parse a token
 	Method    SigningMethod          // The signing method used or to be used
 	Header    map[string]interface{} // The first segment of the token
 	Claims    Claims                 // The second segment of the token
 	Signature string                 // The third segment of the token.  Populated when you Parse a token
 	Valid     bool                   // Is the token valid?  Populated when you Parse/Verify a token
+
+	headerCache *headerCache // Encoded header from the last SigningString.  Set up by New/NewWithClaims
 }
 
 // Create a new Token.  Takes a signing method
@@ -40,69 +63,587 @@ func NewWithClaims(method SigningMethod, claims Claims) *Token {
 			"typ": "JWT",
 			"alg": method.Alg(),
 		},
-		Claims: claims,
-		Method: method,
+		Claims:      claims,
+		Method:      method,
+		headerCache: new(headerCache),
+	}
+}
+
+// Returns a copy of the token that can be modified and signed again without
+// affecting t.  The Header, MapClaims and the built in claims types are
+// deep-copied, including nested maps and slices such as ExtendedClaims.Extra
+// and RegisteredClaims.Audience.  Other claims that are a pointer to a struct
+// point to a copy of the struct, so SetExpiry and Refresh on the clone leave t
+// alone, but the copy is shallow: maps, slices and pointers in a type of your
+// own, even in an embedded RegisteredClaims, are still shared.  Since the copy
+// hasn't been signed, Raw, Parts, Signature and Valid are cleared.
+func (t *Token) Clone() *Token {
+	return &Token{
+		Method:      t.Method,
+		Header:      copyJSONMap(t.Header),
+		Claims:      copyClaims(t.Claims),
+		headerCache: new(headerCache),
+	}
+}
+
+func copyClaims(claims Claims) Claims {
+	switch c := claims.(type) {
+	case MapClaims:
+		return MapClaims(copyJSONMap(c))
+	case RegisteredClaims:
+		return c.deepCopy()
+	case *RegisteredClaims:
+		if c != nil {
+			copied := c.deepCopy()
+			return &copied
+		}
+	case ExtendedClaims:
+		return c.deepCopy()
+	case *ExtendedClaims:
+		if c != nil {
+			copied := c.deepCopy()
+			return &copied
+		}
+	}
+	if v := reflect.ValueOf(claims); v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(v.Elem())
+		return c.Interface().(Claims)
+	}
+	return claims
+}
+
+func copyJSONMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = copyJSONValue(v)
 	}
+	return c
+}
+
+// Copies the mutable values JSON decodes to, and the slice types commonly
+// used when building claims.  Anything else is returned as is.
+func copyJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return copyJSONMap(v)
+	case MapClaims:
+		return MapClaims(copyJSONMap(v))
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = copyJSONValue(e)
+		}
+		return c
+	case []string:
+		return append([]string(nil), v...)
+	case ClaimStrings:
+		return append(ClaimStrings(nil), v...)
+	}
+	return v
+}
+
+// Returns a random identifier suitable for the jti claim: 128 bits from
+// crypto/rand, base64url encoded without padding (22 characters).
+func NewTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails if the OS can't supply randomness at all
+		panic(err)
+	}
+	return EncodeSegment(b)
 }
 
 // Get the complete, signed token
 func (t *Token) SignedString(key interface{}) (string, error) {
+	tokenString, _, err := t.SignedStringAndInput(key)
+	return tokenString, err
+}
+
+// Like SignedString, but also returns the signing string that was signed,
+// without marshaling the header and claims a second time.
+func (t *Token) SignedStringAndInput(key interface{}) (tokenString, signingString string, err error) {
 	var sig, sstr string
-	var err error
 	if sstr, err = t.SigningString(); err != nil {
-		return "", err
+		return "", "", err
 	}
 	if sig, err = t.Method.Sign(sstr, key); err != nil {
-		return "", err
+		return "", "", err
 	}
-	return strings.Join([]string{sstr, sig}, "."), nil
+	return strings.Join([]string{sstr, sig}, "."), sstr, nil
+}
+
+// Like SignedString, but writes the token to w.  The built in methods other
+// than EdDSA hash the header and claims as they are encoded and written, so
+// the signing string of a large token is never held in memory.  The output
+// is the same as SignedString's.  The key is checked before anything is
+// written, but w may hold part of a token if a later step fails.
+func (t *Token) WriteSignedString(w io.Writer, key interface{}) error {
+	signer, ok := t.Method.(digestSigner)
+	if !ok {
+		tokenString, err := t.SignedString(key)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, tokenString)
+		return err
+	}
+
+	unencoded := unencodedPayload(t.Header)
+	if unencoded && !isCritical(t.Header, "b64") {
+		return ErrB64NotCritical
+	}
+	header, err := t.encodedHeader()
+	if err != nil {
+		return err
+	}
+	claims, err := Marshal(t.Claims)
+	if err != nil {
+		return err
+	}
+	if unencoded && bytes.IndexByte(claims, '.') >= 0 {
+		return ErrUnencodedPayloadPeriod
+	}
+
+	hasher, err := signer.signingHash(key)
+	if err != nil {
+		return err
+	}
+	out := io.MultiWriter(w, hasher)
+	if _, err = out.Write(header); err != nil {
+		return err
+	}
+	if _, err = io.WriteString(out, "."); err != nil {
+		return err
+	}
+	if unencoded {
+		_, err = out.Write(claims)
+	} else {
+		enc := base64.NewEncoder(base64.RawURLEncoding, out)
+		if _, err = enc.Write(claims); err == nil {
+			err = enc.Close()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	sig, err := signer.signDigest(rand.Reader, hasher.Sum(nil), key)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "."+sig)
+	return err
 }
 
 // Generate the signing string.  This is the
 // most expensive part of the whole deal.  Unless you
 // need this for something special, just go straight for
 // the SignedString.
+//
+// If the header sets "b64" to false, the claims are left unencoded as per
+// RFC 7797, and "b64" must also be listed in the "crit" header.
+//
+// The output is deterministic: the header and MapClaims are encoded with
+// their keys sorted, and struct claims in field order, so the same claims
+// always produce the same string.  Use ExtendedClaims to combine
+// RegisteredClaims with arbitrary extra claims without losing that.
 func (t *Token) SigningString() (string, error) {
-	var err error
-	parts := make([]string, 2)
-	for i, _ := range parts {
-		var jsonValue []byte
-		if i == 0 {
-			if jsonValue, err = json.Marshal(t.Header); err != nil {
-				return "", err
-			}
-		} else {
-			if jsonValue, err = json.Marshal(t.Claims); err != nil {
-				return "", err
-			}
+	unencoded := unencodedPayload(t.Header)
+	if unencoded && !isCritical(t.Header, "b64") {
+		return "", ErrB64NotCritical
+	}
+
+	header, err := t.encodedHeader()
+	if err != nil {
+		return "", err
+	}
+	claims, err := Marshal(t.Claims)
+	if err != nil {
+		return "", err
+	}
+
+	// Encode both segments into a single buffer sized up front
+	enc := base64.RawURLEncoding
+	headerLen := len(header)
+	claimsLen := enc.EncodedLen(len(claims))
+	if unencoded {
+		// A period would make the compact serialization ambiguous
+		if bytes.IndexByte(claims, '.') >= 0 {
+			return "", ErrUnencodedPayloadPeriod
+		}
+		claimsLen = len(claims)
+	}
+
+	buf := make([]byte, headerLen+1+claimsLen)
+	copy(buf, header)
+	buf[headerLen] = '.'
+	if unencoded {
+		copy(buf[headerLen+1:], claims)
+	} else {
+		enc.Encode(buf[headerLen+1:], claims)
+	}
+	return string(buf), nil
+}
+
+// Refresh errors
+var (
+	ErrRefreshUnverified        = errors.New("token must be verified before it is refreshed")
+	ErrRefreshNoMethod          = errors.New("token has no signing method")
+	ErrRefreshUnsupportedClaims = errors.New("claims type does not support refreshing")
+)
+
+// Sets exp to extension from now and iat to now, then signs the token again
+// with its own method.  Use this to renew a session token without building
+// the claims from scratch.  The claims must be MapClaims, *StandardClaims,
+// *RegisteredClaims, or a pointer to a type that embeds one of the latter.
+//
+// A parsed token must have been verified first.  On success the old Raw,
+// Parts and Signature are cleared, since they no longer match the claims.
+func (t *Token) Refresh(extension time.Duration, key interface{}) (string, error) {
+	if t.Raw != "" && !t.Valid {
+		return "", ErrRefreshUnverified
+	}
+	if t.Method == nil {
+		return "", ErrRefreshNoMethod
+	}
+	c, ok := t.Claims.(timeClaims)
+	if !ok {
+		return "", ErrRefreshUnsupportedClaims
+	}
+
+	now := TimeFunc()
+	c.setTimeClaim("exp", now.Add(extension).Unix())
+	c.setTimeClaim("iat", now.Unix())
+
+	tokenString, err := t.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+	t.Raw, t.Parts, t.Signature, t.Valid = "", nil, "", false
+	return tokenString, nil
+}
+
+// Claims types that can have their exp, iat and nbf set.  name is one of
+// those three, and unix is in seconds.
+type timeClaims interface {
+	setTimeClaim(name string, unix int64)
+}
+
+// Sets the exp claim to exp, in whole seconds as NumericDate requires.  This
+// works for MapClaims, *StandardClaims, *RegisteredClaims, and pointers to
+// types embedding one of the latter.  For any other claims type, including
+// those struct types passed by value, it does nothing.
+func (t *Token) SetExpiry(exp time.Time) {
+	t.setTimeClaim("exp", exp)
+}
+
+// Sets the nbf claim.  See SetExpiry for the claims types supported.
+func (t *Token) SetNotBefore(nbf time.Time) {
+	t.setTimeClaim("nbf", nbf)
+}
+
+// Sets the iat claim.  See SetExpiry for the claims types supported.
+func (t *Token) SetIssuedAt(iat time.Time) {
+	t.setTimeClaim("iat", iat)
+}
+
+// Claims types whose exp can be read without knowing the type.  Zero means
+// there is none.
+type expiryClaims interface {
+	expiresAt() int64
+}
+
+// Returns the time left until the exp claim, measured with TimeFunc, and
+// whether there is an exp.  The duration is negative once the token has
+// expired.  Like ValidateClaims this reads the claims as they are; it works
+// for MapClaims, StandardClaims, RegisteredClaims and types embedding them.
+// In MapClaims, exp may be a float64, json.Number or numeric string.  An exp
+// of zero counts as none, as it does in validation; Parser.RequireExpiry
+// rejects such tokens.
+func (t *Token) ExpiresIn() (time.Duration, bool) {
+	c, ok := t.Claims.(expiryClaims)
+	if !ok {
+		return 0, false
+	}
+	exp := c.expiresAt()
+	if exp == 0 {
+		return 0, false
+	}
+	return time.Unix(exp, 0).Sub(TimeFunc()), true
+}
+
+func (t *Token) setTimeClaim(name string, value time.Time) {
+	if c, ok := t.Claims.(timeClaims); ok {
+		c.setTimeClaim(name, value.Unix())
+	}
+}
+
+// Returns the base64url encoded header segment, reusing the previous result
+// if the header hasn't changed since
+func (t *Token) encodedHeader() ([]byte, error) {
+	if t.headerCache != nil {
+		if encoded, ok := t.headerCache.get(t.Header); ok {
+			return encoded, nil
 		}
+	}
+
+	header, err := Marshal(t.Header)
+	if err != nil {
+		return nil, err
+	}
+	encoded := make([]byte, base64.RawURLEncoding.EncodedLen(len(header)))
+	base64.RawURLEncoding.Encode(encoded, header)
+
+	if t.headerCache != nil {
+		t.headerCache.set(t.Header, encoded)
+	}
+	return encoded, nil
+}
+
+// Remembers an encoded header along with a copy of the header it came from,
+// so changes to the header map are noticed.  Only headers made up of simple
+// values are cached, since other values can't be compared cheaply.
+type headerCache struct {
+	mu       sync.Mutex
+	snapshot map[string]interface{}
+	encoded  []byte
+}
+
+func (c *headerCache) get(header map[string]interface{}) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.encoded == nil || len(header) != len(c.snapshot) {
+		return nil, false
+	}
+	for k, v := range header {
+		// Snapshot values are always comparable, so this can't panic
+		if cached, ok := c.snapshot[k]; !ok || cached != v {
+			return nil, false
+		}
+	}
+	return c.encoded, true
+}
+
+func (c *headerCache) set(header map[string]interface{}, encoded []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.snapshot, c.encoded = nil, nil
+	snapshot := make(map[string]interface{}, len(header))
+	for k, v := range header {
+		switch v.(type) {
+		case string, bool, float64, int, int64, json.Number:
+			snapshot[k] = v
+		default:
+			return
+		}
+	}
+	c.snapshot, c.encoded = snapshot, encoded
+}
+
+// Returns the "kid" header, which identifies the key used to sign the token,
+// or an empty string if it is missing or not a string.
+func (t *Token) KeyID() string {
+	kid, _ := t.Header["kid"].(string)
+	return kid
+}
+
+// Returns the named header as a string, and whether it was present as one.
+// Useful for parameters such as jku or x5t that have no accessor of their own.
+func (t *Token) HeaderString(name string) (string, bool) {
+	s, ok := t.Header[name].(string)
+	return s, ok
+}
+
+// Returns the "alg" header, or an empty string if it is missing or not a string.
+// Unlike Method, this is exactly what the token states.
+func (t *Token) Alg() string {
+	alg, _ := t.Header["alg"].(string)
+	return alg
+}
+
+// Returns the "typ" header, or an empty string if it is missing or not a string.
+func (t *Token) Typ() string {
+	typ, _ := t.Header["typ"].(string)
+	return typ
+}
 
-		parts[i] = EncodeSegment(jsonValue)
+// Returns the base64url encoded SHA-256 of Raw, a compact identifier for
+// denylists and logs that doesn't reveal the token.  It is the same for every
+// parse of the same string.  Returns an empty string for a token that wasn't
+// parsed, as it has no Raw.
+func (t *Token) Fingerprint() string {
+	if t.Raw == "" {
+		return ""
 	}
-	return strings.Join(parts, "."), nil
+	sum := sha256.Sum256([]byte(t.Raw))
+	return EncodeSegment(sum[:])
+}
+
+// Runs the claims' own validation, as Parse does, without touching the
+// signature.  Handy when building or modifying a token in code.  This is a
+// method rather than Valid because Valid already holds the result of signature
+// verification.  No Parser settings are applied: no leeway, RequiredClaims,
+// RequireExpiry, ExpectedAudience, ExpectedIssuer or ClaimsValidator.  Use
+// Parser.ValidateClaims to re-validate a token as its Parser would.
+func (t *Token) ValidateClaims() error {
+	if t.Claims == nil {
+		return NewValidationError("token has no claims", ValidationErrorClaimsInvalid)
+	}
+	if err := t.Claims.Valid(); err != nil {
+		return claimsValidationError(err)
+	}
+	return nil
+}
+
+// Checks only the signature of a token from ParseUnverified against key,
+// using the token's method.  Claims are left alone; see ValidateClaims.
+// The error is whatever the method's Verify returns.  Valid is not changed.
+// It expects base64url, so it can fail for a token parsed with an Encoding.
+func (t *Token) SignatureValid(key interface{}) error {
+	if len(t.Parts) != 3 || t.Method == nil {
+		return errors.New("token has not been parsed")
+	}
+	return t.Method.Verify(t.Parts[0]+"."+t.Parts[1], t.Parts[2], key)
 }
 
 // Parse, validate, and return a token.
 // keyFunc will receive the parsed token and should return the key for validating.
+// Options configure the parser, as with NewParser.
 // If everything is kosher, err will be nil
-func Parse(tokenString string, keyFunc Keyfunc) (*Token, error) {
-	return new(Parser).Parse(tokenString, keyFunc)
+func Parse(tokenString string, keyFunc Keyfunc, options ...ParserOption) (*Token, error) {
+	return NewParser(options...).Parse(tokenString, keyFunc)
 }
 
-func ParseWithClaims(tokenString string, claims Claims, keyFunc Keyfunc) (*Token, error) {
-	return new(Parser).ParseWithClaims(tokenString, claims, keyFunc)
+func ParseWithClaims(tokenString string, claims Claims, keyFunc Keyfunc, options ...ParserOption) (*Token, error) {
+	return NewParser(options...).ParseWithClaims(tokenString, claims, keyFunc)
+}
+
+// Reports whether the header opts out of encoding the payload, as per RFC 7797
+func unencodedPayload(header map[string]interface{}) bool {
+	b64, ok := header["b64"].(bool)
+	return ok && !b64
+}
+
+// Reports whether name is listed in the crit header
+func isCritical(header map[string]interface{}, name string) bool {
+	switch crit := header["crit"].(type) {
+	case []interface{}:
+		for _, c := range crit {
+			if c == name {
+				return true
+			}
+		}
+	case []string:
+		for _, c := range crit {
+			if c == name {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Encode JWT specific base64url encoding with padding stripped
 func EncodeSegment(seg []byte) string {
-	return strings.TrimRight(base64.URLEncoding.EncodeToString(seg), "=")
+	return base64.RawURLEncoding.EncodeToString(seg)
 }
 
-// Decode JWT specific base64url encoding with padding stripped
+// Decode JWT specific base64url encoding with padding stripped.
+// As required by the JWS spec, segments that include "=" padding are rejected.
+// So is anything outside the URL-safe alphabet, including the line breaks
+// encoding/base64 would otherwise skip, and any encoding with non-zero
+// trailing bits, so each decoded value has exactly one valid segment.
 func DecodeSegment(seg string) ([]byte, error) {
-	if l := len(seg) % 4; l > 0 {
-		seg += strings.Repeat("=", 4-l)
+	if i := strings.IndexAny(seg, "\r\n"); i >= 0 {
+		return nil, base64.CorruptInputError(i)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return nil, err
+	}
+	// Reject non-zero trailing bits by re-encoding, as Strict, which needs
+	// Go 1.8, would
+	if base64.RawURLEncoding.EncodeToString(b) != seg {
+		return nil, base64.CorruptInputError(len(seg) - 1)
+	}
+	return b, nil
+}
+
+// Splits a compact token into its three segments without decoding them, as a
+// cheap syntax check before any crypto.  Each segment must be non-empty,
+// unpadded base64url that DecodeSegment would accept.  Nothing is allocated
+// unless the token is rejected.  Unsigned tokens, which may have two
+// segments or an empty signature, fail the check.
+func SplitToken(s string) (header, claims, sig string, err error) {
+	i := strings.IndexByte(s, '.')
+	j := -1
+	if i >= 0 {
+		if j = strings.IndexByte(s[i+1:], '.'); j >= 0 {
+			j += i + 1
+		}
+	}
+	if j < 0 || strings.IndexByte(s[j+1:], '.') >= 0 {
+		return "", "", "", NewValidationError("token contains an invalid number of segments", ValidationErrorMalformed)
+	}
+
+	header, claims, sig = s[:i], s[i+1:j], s[j+1:]
+	for _, seg := range []string{header, claims, sig} {
+		if seg == "" {
+			return "", "", "", NewValidationError("token contains an empty segment", ValidationErrorMalformed)
+		}
+		if !isBase64URL(seg) {
+			return "", "", "", NewValidationError("token segment is not valid base64url", ValidationErrorMalformed)
+		}
 	}
+	return header, claims, sig, nil
+}
 
-	return base64.URLEncoding.DecodeString(seg)
+// Reports whether s passes SplitToken
+func IsWellFormed(s string) bool {
+	_, _, _, err := SplitToken(s)
+	return err == nil
+}
+
+// Reports whether DecodeSegment would accept seg, without decoding it
+func isBase64URL(seg string) bool {
+	if len(seg)%4 == 1 {
+		return false
+	}
+	for i := 0; i < len(seg); i++ {
+		if base64URLValue(seg[i]) < 0 {
+			return false
+		}
+	}
+
+	// As with Strict decoding, the unused bits of the last character must be zero
+	switch last := base64URLValue(seg[len(seg)-1]); len(seg) % 4 {
+	case 2:
+		return last&0xf == 0
+	case 3:
+		return last&0x3 == 0
+	}
+	return true
+}
+
+// Returns the value of a base64url character, or -1 if c isn't one
+func base64URLValue(c byte) int {
+	switch {
+	case c >= 'A' && c <= 'Z':
+		return int(c - 'A')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 26
+	case c >= '0' && c <= '9':
+		return int(c-'0') + 52
+	case c == '-':
+		return 62
+	case c == '_':
+		return 63
+	}
+	return -1
 }
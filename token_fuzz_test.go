@@ -0,0 +1,26 @@
+//go:build go1.18
+// +build go1.18
+
+package jwt_test
+
+import (
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func FuzzDecodeSegment(f *testing.F) {
+	for _, data := range segmentTestData {
+		f.Add(data.segment)
+	}
+	f.Fuzz(func(t *testing.T, seg string) {
+		decoded, err := jwt.DecodeSegment(seg)
+		if err != nil {
+			return
+		}
+		// Anything accepted must be the one canonical encoding of its bytes
+		if reencoded := jwt.EncodeSegment(decoded); reencoded != seg {
+			t.Errorf("Segment %q decoded, but encodes back to %q", seg, reencoded)
+		}
+	})
+}
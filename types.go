@@ -0,0 +1,114 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidAudience is returned when an aud claim is neither a string nor
+// an array of strings
+var ErrInvalidAudience = errors.New("aud claim must be a string or an array of strings")
+
+// ClaimStrings is used for claims, such as aud, that RFC 7519 allows to be
+// either a single string or an array of strings.  Both forms are accepted
+// when decoding.  When encoding, a single value is written as a plain
+// string and anything else as an array, so the output is deterministic.
+type ClaimStrings []string
+
+func (s *ClaimStrings) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	aud, ok := audience(value)
+	if !ok {
+		return ErrInvalidAudience
+	}
+	*s = aud
+	return nil
+}
+
+func (s ClaimStrings) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return Marshal(s[0])
+	}
+	return Marshal([]string(s))
+}
+
+// NumericDate is used for the time claims exp, nbf and iat.  As per RFC
+// 7519 it is encoded as seconds since the Unix epoch; fractional seconds are
+// accepted when decoding but dropped when encoding.  Like MapClaims, it also
+// accepts non-conformant values encoded as a string.
+type NumericDate struct {
+	t time.Time
+}
+
+// Wraps t for use in claims
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{t}
+}
+
+// Returns the wrapped time, or the zero time if date is nil
+func (date *NumericDate) Time() time.Time {
+	if date == nil {
+		return time.Time{}
+	}
+	return date.t
+}
+
+func (date NumericDate) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, date.t.Unix(), 10), nil
+}
+
+func (date *NumericDate) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("could not parse %s as a numeric date", data)
+	}
+	sec, frac := math.Modf(f)
+	date.t = time.Unix(int64(sec), int64(frac*1e9))
+	return nil
+}
+
+// Seconds since the epoch, or zero, meaning unset, if date is nil
+func (date *NumericDate) unix() int64 {
+	if date == nil {
+		return 0
+	}
+	return date.t.Unix()
+}
+
+// Normalizes a decoded aud claim to a list of strings.  Returns false if the
+// value is present but isn't a string or an array of strings.
+func audience(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case ClaimStrings:
+		return v, true
+	case []interface{}:
+		aud := make([]string, 0, len(v))
+		for _, a := range v {
+			vs, ok := a.(string)
+			if !ok {
+				return nil, false
+			}
+			aud = append(aud, vs)
+		}
+		return aud, true
+	}
+	return nil, false
+}
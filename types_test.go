@@ -0,0 +1,153 @@
+package jwt_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var claimStringsTestData = []struct {
+	name     string
+	input    string
+	value    jwt.ClaimStrings
+	output   string
+	hasError bool
+}{
+	{"single string", `"foo"`, jwt.ClaimStrings{"foo"}, `"foo"`, false},
+	{"array of one", `["foo"]`, jwt.ClaimStrings{"foo"}, `"foo"`, false},
+	{"array", `["foo","bar"]`, jwt.ClaimStrings{"foo", "bar"}, `["foo","bar"]`, false},
+	{"empty array", `[]`, jwt.ClaimStrings{}, `[]`, false},
+	{"number", `1`, nil, ``, true},
+	{"array with number", `["foo",1]`, nil, ``, true},
+}
+
+func TestClaimStrings_UnmarshalJSON(t *testing.T) {
+	for _, data := range claimStringsTestData {
+		var value jwt.ClaimStrings
+		err := json.Unmarshal([]byte(data.input), &value)
+		if data.hasError {
+			if err == nil {
+				t.Errorf("[%v] Expected error.  Didn't get one.", data.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%v] Error while unmarshaling: %v", data.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, data.value) {
+			t.Errorf("[%v] Expected %#v.  Got %#v", data.name, data.value, value)
+		}
+	}
+}
+
+func TestClaimStrings_MarshalJSON(t *testing.T) {
+	for _, data := range claimStringsTestData {
+		if data.hasError {
+			continue
+		}
+		b, err := json.Marshal(data.value)
+		if err != nil {
+			t.Errorf("[%v] Error while marshaling: %v", data.name, err)
+			continue
+		}
+		if string(b) != data.output {
+			t.Errorf("[%v] Expected %v.  Got %s", data.name, data.output, b)
+		}
+	}
+}
+
+func TestRegisteredClaims_VerifyAudience(t *testing.T) {
+	for _, raw := range []string{`{"aud":"foo"}`, `{"aud":["bar","foo"]}`} {
+		var claims jwt.RegisteredClaims
+		if err := json.Unmarshal([]byte(raw), &claims); err != nil {
+			t.Fatal(err)
+		}
+		if !claims.VerifyAudience("foo", true) {
+			t.Errorf("[%v] Audience did not match", raw)
+		}
+		if claims.VerifyAudience("baz", false) {
+			t.Errorf("[%v] Mismatched audience passed verification", raw)
+		}
+	}
+}
+
+func TestNumericDate_JSON(t *testing.T) {
+	var numericDateTestData = []struct {
+		name     string
+		input    string
+		expected time.Time
+		valid    bool
+	}{
+		{"integer", `1500000000`, time.Unix(1500000000, 0), true},
+		{"fractional", `1500000000.25`, time.Unix(1500000000, 250000000), true},
+		{"exponent", `1.5e9`, time.Unix(1500000000, 0), true},
+		{"string", `"1500000000"`, time.Unix(1500000000, 0), true},
+		{"garbage string", `"soon"`, time.Time{}, false},
+		{"boolean", `true`, time.Time{}, false},
+	}
+
+	for _, data := range numericDateTestData {
+		var date jwt.NumericDate
+		err := json.Unmarshal([]byte(data.input), &date)
+		if !data.valid {
+			if err == nil {
+				t.Errorf("[%v] Expected an error decoding %v", data.name, data.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%v] Error while decoding: %v", data.name, err)
+			continue
+		}
+		if !date.Time().Equal(data.expected) {
+			t.Errorf("[%v] Expected %v.  Got %v", data.name, data.expected, date.Time())
+		}
+
+		// Encodes back to whole seconds
+		b, err := json.Marshal(&date)
+		if err != nil || string(b) != fmt.Sprint(data.expected.Unix()) {
+			t.Errorf("[%v] Incorrect encoding %s: %v", data.name, b, err)
+		}
+	}
+
+	var nilDate *jwt.NumericDate
+	if !nilDate.Time().IsZero() {
+		t.Errorf("Expected the zero time for a nil NumericDate")
+	}
+}
+
+func TestRegisteredClaims_NumericDates(t *testing.T) {
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Unix(2000, 0)),
+		NotBefore: jwt.NewNumericDate(time.Unix(500, 0)),
+	}
+	b, err := json.Marshal(claims)
+	if err != nil || string(b) != `{"exp":2000,"nbf":500}` {
+		t.Fatalf("Incorrect encoding %s: %v", b, err)
+	}
+
+	var decoded jwt.RegisteredClaims
+	if err := json.Unmarshal([]byte(`{"exp":2000.5,"nbf":"500"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.IssuedAt != nil {
+		t.Errorf("Expected absent iat to stay nil")
+	}
+	at(time.Unix(1000, 0), func() {
+		err = decoded.Valid()
+	})
+	if err != nil {
+		t.Errorf("Error while validating claims: %v", err)
+	}
+	at(time.Unix(2001, 0), func() {
+		err = decoded.Valid()
+	})
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorExpired {
+		t.Errorf("Expected ValidationErrorExpired.  Got %v", err)
+	}
+}
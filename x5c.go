@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Returns the public key of the leaf certificate in the x5c header, which as
+// per RFC 7515 holds the certificate chain of the signing key, leaf first.
+// A Keyfunc can return this directly.
+//
+// If roots is non-nil the chain must verify against it, with any further
+// x5c certificates as intermediates.  Pass nil only if the certificate is
+// checked some other way, since anyone can put a certificate in a header.
+// Expiry is checked against TimeFunc.
+func KeyFromX5C(header map[string]interface{}, roots *x509.CertPool) (interface{}, error) {
+	chain, ok := header["x5c"].([]interface{})
+	if !ok || len(chain) == 0 {
+		return nil, errors.New("x5c header is missing or not an array")
+	}
+
+	certs := make([]*x509.Certificate, len(chain))
+	for i, v := range chain {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("x5c certificate %d is not a string", i)
+		}
+		// Unlike the rest of a JWT, these are standard base64 with padding
+		der, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("x5c certificate %d: %v", i, err)
+		}
+		if certs[i], err = x509.ParseCertificate(der); err != nil {
+			return nil, fmt.Errorf("x5c certificate %d: %v", i, err)
+		}
+	}
+
+	if roots != nil {
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   TimeFunc(),
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("x5c chain does not verify: %v", err)
+		}
+	}
+
+	return certs[0].PublicKey, nil
+}
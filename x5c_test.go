@@ -0,0 +1,132 @@
+package jwt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/dgrijalva/jwt-go/test"
+)
+
+// Creates a certificate for key, signed by parent's key, or self-signed if
+// parent is nil
+func makeTestCert(t *testing.T, name string, key *rsa.PrivateKey, parent *x509.Certificate, parentKey *rsa.PrivateKey) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Unix(1000, 0),
+		NotAfter:              time.Unix(2000000000, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func generateTestRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeyFromX5C(t *testing.T) {
+	key := test.LoadRSAPrivateKeyFromDisk("test/sample_key")
+	cert := makeTestCert(t, "signer", key, nil, nil)
+	otherCert := makeTestCert(t, "other", generateTestRSAKey(t), nil, nil)
+
+	trusted := x509.NewCertPool()
+	trusted.AddCert(cert)
+	untrusted := x509.NewCertPool()
+	untrusted.AddCert(otherCert)
+
+	x5c := []interface{}{base64.StdEncoding.EncodeToString(cert.Raw)}
+
+	var x5cTestData = []struct {
+		name   string
+		header map[string]interface{}
+		roots  *x509.CertPool
+		error  string
+	}{
+		{"trusted", map[string]interface{}{"x5c": x5c}, trusted, ""},
+		{"not verified", map[string]interface{}{"x5c": x5c}, nil, ""},
+		{"untrusted", map[string]interface{}{"x5c": x5c}, untrusted, "x5c chain does not verify"},
+		{"missing", map[string]interface{}{}, trusted, "x5c header is missing or not an array"},
+		{"empty", map[string]interface{}{"x5c": []interface{}{}}, trusted, "x5c header is missing or not an array"},
+		{"not an array", map[string]interface{}{"x5c": x5c[0]}, trusted, "x5c header is missing or not an array"},
+		{"not a string", map[string]interface{}{"x5c": []interface{}{float64(1)}}, trusted, "x5c certificate 0 is not a string"},
+		{"bad base64", map[string]interface{}{"x5c": []interface{}{"not base64!"}}, trusted, "x5c certificate 0: illegal base64"},
+		{"bad certificate", map[string]interface{}{"x5c": []interface{}{"AAAA"}}, trusted, "x5c certificate 0: x509:"},
+	}
+
+	for _, data := range x5cTestData {
+		publicKey, err := jwt.KeyFromX5C(data.header, data.roots)
+		if data.error == "" {
+			if err != nil {
+				t.Errorf("[%v] Error while reading x5c: %v", data.name, err)
+			} else if pk, ok := publicKey.(*rsa.PublicKey); !ok || pk.N.Cmp(key.N) != 0 {
+				t.Errorf("[%v] Expected the certificate's public key.  Got %v", data.name, publicKey)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), data.error) {
+			t.Errorf("[%v] Errors don't match expectation.  %v != %v", data.name, err, data.error)
+		}
+	}
+}
+
+func TestKeyFromX5C_Parse(t *testing.T) {
+	rootKey := generateTestRSAKey(t)
+	root := makeTestCert(t, "root", rootKey, nil, nil)
+	key := test.LoadRSAPrivateKeyFromDisk("test/sample_key")
+	leaf := makeTestCert(t, "signer", key, root, rootKey)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"foo": "bar"})
+	token.Header["x5c"] = []string{
+		base64.StdEncoding.EncodeToString(leaf.Raw),
+		base64.StdEncoding.EncodeToString(root.Raw),
+	}
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return jwt.KeyFromX5C(token.Header, roots)
+	})
+	if err != nil || !token.Valid {
+		t.Errorf("Error while verifying token: %v", err)
+	}
+
+	// Expired by the time of parsing
+	at(time.Unix(2000000001, 0), func() {
+		_, err = jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return jwt.KeyFromX5C(token.Header, roots)
+		})
+	})
+	if ve, ok := err.(*jwt.ValidationError); !ok || ve.Errors != jwt.ValidationErrorUnverifiable {
+		t.Errorf("Expected an unverifiable token.  Got %v", err)
+	}
+}